@@ -106,6 +106,8 @@ def plot_speedups(num_threads, speedups, mode):
     plt.grid()
     if mode == "ws":
         mode = "Work-Stealing"
+    elif mode == "wsh":
+        mode = "Work-Sharing"
     else:
         mode = "Work-Balancing"
     plt.savefig(f'{mode}-speedup.png')
@@ -121,7 +123,8 @@ if __name__ == '__main__':
 
     parallel_modes = [
         'ws',
-        'wb'
+        'wb',
+        'wsh'
     ]
 
     # The number of threads to benchmark with.
@@ -0,0 +1,162 @@
+package concurrent
+
+import (
+	"math/rand"
+)
+
+// BalancePolicy decides when a worker of the work-balancing executor balances, which worker it
+// balances with and how many tasks are moved from the larger to the smaller of the two queues.
+// The state of the worker is passed in so that a policy can be shared by all workers.
+type BalancePolicy interface {
+	// Trigger is called after every iteration of the worker with the number of iterations so far
+	// and the size of its local queue, it returns whether the worker should balance now
+	Trigger(iteration, size int, randGen *rand.Rand) bool
+	// Pair returns the index of the worker to balance with among capacity workers, size returns
+	// the size of a worker's queue
+	Pair(id, capacity int, victims VictimSelector, size func(worker int) int) int
+	// Amount returns the number of tasks to move from the larger to the smaller queue
+	Amount(smallSize, largeSize, threshold int) int
+}
+
+// BalanceTrigger decides whether a worker balances after an iteration
+type BalanceTrigger func(iteration, size int, randGen *rand.Rand) bool
+
+// BalancePairing picks the worker to balance with
+type BalancePairing func(id, capacity int, victims VictimSelector, size func(worker int) int) int
+
+// BalanceAmount returns the number of tasks to move from the larger to the smaller queue
+type BalanceAmount func(smallSize, largeSize, threshold int) int
+
+// Balance policy composed of a trigger, a pairing and an amount
+type balancePolicy struct {
+	trigger BalanceTrigger
+	pairing BalancePairing
+	amount  BalanceAmount
+}
+
+// NewBalancePolicy returns a BalancePolicy composed of the given trigger, pairing and amount
+func NewBalancePolicy(trigger BalanceTrigger, pairing BalancePairing, amount BalanceAmount) BalancePolicy {
+	return &balancePolicy{
+		trigger: trigger,
+		pairing: pairing,
+		amount:  amount,
+	}
+}
+
+// DefaultBalancePolicy balances at random with the worker's victim and moves tasks until the
+// difference in the sizes of the queues drops below the balancing threshold
+func DefaultBalancePolicy() BalancePolicy {
+	return NewBalancePolicy(TriggerRandom(), PairVictim(), MoveBelowThreshold())
+}
+
+func (policy *balancePolicy) Trigger(iteration, size int, randGen *rand.Rand) bool {
+	return policy.trigger(iteration, size, randGen)
+}
+
+func (policy *balancePolicy) Pair(id, capacity int, victims VictimSelector, size func(worker int) int) int {
+	return policy.pairing(id, capacity, victims, size)
+}
+
+func (policy *balancePolicy) Amount(smallSize, largeSize, threshold int) int {
+	return policy.amount(smallSize, largeSize, threshold)
+}
+
+// TriggerRandom balances at random (i.e. 1/(n+1) chance where n is the size of the local queue)
+func TriggerRandom() BalanceTrigger {
+	return func(iteration, size int, randGen *rand.Rand) bool {
+		return size == randGen.Intn(size+1)
+	}
+}
+
+// TriggerPeriodic balances every period iterations
+func TriggerPeriodic(period int) BalanceTrigger {
+	// Balance at least every iteration
+	if period < 1 {
+		period = 1
+	}
+	return func(iteration, size int, randGen *rand.Rand) bool {
+		return iteration%period == 0
+	}
+}
+
+// TriggerOnEmpty balances whenever the local queue is empty
+func TriggerOnEmpty() BalanceTrigger {
+	return func(iteration, size int, randGen *rand.Rand) bool {
+		return size == 0
+	}
+}
+
+// PairVictim balances with the next victim of the worker's victim selector
+func PairVictim() BalancePairing {
+	return func(id, capacity int, victims VictimSelector, size func(worker int) int) int {
+		return victims.Next(size)
+	}
+}
+
+// PairMostImbalanced balances with the worker whose queue size differs the most from the local queue
+func PairMostImbalanced() BalancePairing {
+	return func(id, capacity int, victims VictimSelector, size func(worker int) int) int {
+		ownSize := size(id)
+		pair := -1
+		maxDiff := -1
+		for worker := 0; worker < capacity; worker++ {
+			if worker == id {
+				continue
+			}
+
+			diff := size(worker) - ownSize
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > maxDiff {
+				pair = worker
+				maxDiff = diff
+			}
+		}
+		return pair
+	}
+}
+
+// MoveBelowThreshold moves tasks until the difference in the sizes drops below the threshold,
+// as many as moving them one at a time while the difference reaches the threshold. A threshold
+// below 1 is taken as 1 (the sizes end up differing by at most one) since the difference of two
+// queues cannot drop below it.
+func MoveBelowThreshold() BalanceAmount {
+	return func(smallSize, largeSize, threshold int) int {
+		if threshold < 1 {
+			threshold = 1
+		}
+		diff := largeSize - smallSize
+		if diff < threshold {
+			return 0
+		}
+		// Every task moved reduces the difference by two
+		return (diff-threshold)/2 + 1
+	}
+}
+
+// MoveUntilEqual moves tasks until the sizes differ by at most one once the difference
+// reaches the threshold
+func MoveUntilEqual() BalanceAmount {
+	return func(smallSize, largeSize, threshold int) int {
+		diff := largeSize - smallSize
+		if diff < threshold || diff <= 0 {
+			return 0
+		}
+		return diff / 2
+	}
+}
+
+// MoveFixed moves a fixed number of tasks (at most the size of the larger queue) once the
+// difference in the sizes reaches the threshold
+func MoveFixed(count int) BalanceAmount {
+	return func(smallSize, largeSize, threshold int) int {
+		if largeSize-smallSize < threshold || largeSize <= 0 || count <= 0 {
+			return 0
+		}
+		if count > largeSize {
+			return largeSize
+		}
+		return count
+	}
+}
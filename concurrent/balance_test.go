@@ -0,0 +1,104 @@
+package concurrent
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Number of tasks moved one at a time while the difference in the sizes reaches the threshold
+// (how the work balancing workers balanced before the balance policies)
+func movedOneAtATime(smallSize, largeSize, threshold int) int {
+	moved := 0
+	for largeSize-smallSize >= threshold && largeSize > 0 {
+		smallSize++
+		largeSize--
+		moved++
+	}
+	return moved
+}
+
+func TestMoveBelowThreshold(t *testing.T) {
+	amount := MoveBelowThreshold()
+	for threshold := -2; threshold <= 6; threshold++ {
+		for small := 0; small <= 8; small++ {
+			for large := small; large <= 20; large++ {
+				// The one at a time loop only ends for a positive threshold
+				expected := movedOneAtATime(small, large, threshold)
+				if threshold < 1 {
+					expected = movedOneAtATime(small, large, 1)
+				}
+				if moved := amount(small, large, threshold); moved != expected {
+					t.Fatalf("moved %d tasks between queues of %d and %d (threshold %d), expected %d", moved, small, large, threshold, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestBalanceAmounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    BalanceAmount
+		small     int
+		large     int
+		threshold int
+		moved     int
+	}{
+		{"equal", MoveUntilEqual(), 0, 10, 2, 5},
+		{"equal", MoveUntilEqual(), 3, 8, 5, 2},
+		{"equal", MoveUntilEqual(), 3, 8, 6, 0},
+		{"equal", MoveUntilEqual(), 0, 1, 2, 0},
+		{"equal", MoveUntilEqual(), 4, 4, 0, 0},
+		{"equal", MoveUntilEqual(), 5, 3, -4, 0},
+		{"fixed", MoveFixed(3), 0, 10, 2, 3},
+		{"fixed", MoveFixed(3), 0, 2, 1, 2},
+		{"fixed", MoveFixed(3), 0, 1, 2, 0},
+		{"fixed", MoveFixed(3), 0, 0, 0, 0},
+		{"fixed", MoveFixed(0), 0, 10, 2, 0},
+	}
+	for _, test := range tests {
+		if moved := test.amount(test.small, test.large, test.threshold); moved != test.moved {
+			t.Errorf("%s moved %d tasks between queues of %d and %d (threshold %d), expected %d",
+				test.name, moved, test.small, test.large, test.threshold, test.moved)
+		}
+	}
+}
+
+func TestBalanceTriggers(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name      string
+		trigger   BalanceTrigger
+		iteration int
+		size      int
+		balances  bool
+	}{
+		{"periodic", TriggerPeriodic(3), 1, 0, false},
+		{"periodic", TriggerPeriodic(3), 2, 5, false},
+		{"periodic", TriggerPeriodic(3), 3, 5, true},
+		{"periodic", TriggerPeriodic(3), 6, 0, true},
+		{"periodic", TriggerPeriodic(0), 1, 5, true},
+		{"periodic", TriggerPeriodic(-3), 2, 5, true},
+		{"empty", TriggerOnEmpty(), 1, 0, true},
+		{"empty", TriggerOnEmpty(), 1, 1, false},
+		{"empty", TriggerOnEmpty(), 3, 10, false},
+		{"random", TriggerRandom(), 1, 0, true},
+	}
+	for _, test := range tests {
+		if balances := test.trigger(test.iteration, test.size, randGen); balances != test.balances {
+			t.Errorf("%s trigger balances after iteration %d with %d tasks: %v, expected %v",
+				test.name, test.iteration, test.size, balances, test.balances)
+		}
+	}
+}
+
+func TestPairMostImbalanced(t *testing.T) {
+	sizes := []int{5, 2, 12, 4}
+	size := func(worker int) int { return sizes[worker] }
+	pairing := PairMostImbalanced()
+	for id, expected := range []int{2, 2, 1, 2} {
+		if pair := pairing(id, len(sizes), nil, size); pair != expected {
+			t.Errorf("worker %d balances with %d, expected %d", id, pair, expected)
+		}
+	}
+}
@@ -1,141 +1,109 @@
 package concurrent
 
-import (
-	"math/rand"
-	"sync"
-	"time"
-)
-
 // Shared Context for Work Balancing
 type sharedContextWB struct {
-	capacity         int
-	thresholdQueue   int
+	*poolContext
 	thresholdBalance int
-	queues           []DEQueue
-	wg               *sync.WaitGroup
+	balancePolicy    BalancePolicy
 }
 
 // Work Balancing Balancer
 type balancer struct {
-	workers         []*workerWB
-	done            bool
-	prevDistributee int
-	context         *sharedContextWB
+	*workerPool
 }
 
 // Work Balancing Worker
 type workerWB struct {
-	id            int
-	context       *sharedContextWB
-	randGen       *rand.Rand
-	workRemaining bool
+	*poolWorker
+	context *sharedContextWB
 }
 
-// Returns a new Work Balancing Balancer
+// Returns a new Work Balancing Worker
 func NewWorkerWB(id int, context *sharedContextWB) *workerWB {
 	return &workerWB{
-		id:            id,
-		context:       context,
-		randGen:       rand.New(rand.NewSource(time.Now().UnixNano())),
-		workRemaining: true,
-	}
-}
-
-// Check if all queues are empty
-func (worker *workerWB) isWorkPoolEmpty() bool {
-	// Check if all queues are empty
-	for _, queue := range worker.context.queues {
-		if !queue.IsEmpty() {
-			return false
-		}
+		poolWorker: newPoolWorker(id, context.poolContext),
+		context:    context,
 	}
-	return true
-}
-
-// Get a random victim
-func (worker *workerWB) getVictim() int {
-	// Get random victim
-	victim := worker.randGen.Intn(worker.context.capacity)
-
-	// Make sure the victim is not the worker
-	for victim == worker.id {
-		victim = worker.randGen.Intn(worker.context.capacity)
-	}
-	return victim
-}
-
-// Balance policy - returns true if the queues need to be balanced based on the balancing threshold provided
-func balancePolicy(smallQueue, largeQueue DEQueue, lambda int) bool {
-	// Check if the queues need to be balanced
-	return largeQueue.Size()-smallQueue.Size() >= lambda
 }
 
-// Balance two queues
+// Balance the local queue with the queue of the worker chosen by the balance policy
 func (worker *workerWB) balance() {
-	// Get the victim to balance with
-	victim := worker.getVictim()
-
-	// Ordering of victim and worker
-	var min int
-	var max int
-	if victim < worker.id {
-		min = victim
-		max = worker.id
-	} else {
-		min = worker.id
-		max = victim
-	}
+	// Get the worker to balance with
+	victim := worker.context.balancePolicy.Pair(worker.id, worker.capacity, worker.victims, worker.queueSize)
 
 	// Determine which queue is smaller and which is larger
-	var smallQueue DEQueue
-	var largeQueue DEQueue
+	small, large := victim, worker.id
+	if worker.queue.Size() < worker.queues[victim].Size() {
+		small, large = worker.id, victim
+	}
 
 	// Get the queues to balance
-	if worker.context.queues[min].Size() < worker.context.queues[max].Size() {
-		smallQueue = worker.context.queues[min]
-		largeQueue = worker.context.queues[max]
-	} else {
-		smallQueue = worker.context.queues[max]
-		largeQueue = worker.context.queues[min]
-	}
+	smallQueue := worker.queues[small]
+	largeQueue := worker.queues[large]
+
+	// Ask the balance policy how many tasks need to be moved
+	amount := worker.context.balancePolicy.Amount(smallQueue.Size(), largeQueue.Size(), worker.context.thresholdBalance)
 
-	// Check if the queues need to be balanced
-	for balancePolicy(smallQueue, largeQueue, worker.context.thresholdBalance) {
+	moved := 0
+	for moved < amount {
 		// Get the task to move
-		job := largeQueue.PopBottom()
+		job := largeQueue.PopTop()
+		if job == nil {
+			break
+		}
 
 		// Add the task to the small queue
-		if job != nil {
-			smallQueue.PushBottom(job)
-		}
+		smallQueue.PushBottom(job)
+		moved++
 	}
+
+	// Let the victim selector know how the balancing went
+	worker.victims.Report(victim, moved > 0)
+	worker.stats.balanceCheck(large, small, moved)
 }
 
 // Worker routine
 func (worker *workerWB) work() {
 	// Worker loops if work is remaining in the overall work pool and if worker's local queue is not empty
-	for worker.workRemaining || !worker.isWorkPoolEmpty() {
-		// Get the next task
-		workerTask := worker.context.queues[worker.id].PopTop()
+	// (the idle epoch is observed before checking for work so that no wakeup is missed)
+	iteration := 0
+	for epoch := worker.idler.observe(); worker.running(); epoch = worker.idler.observe() {
+		worker.refresh()
+
+		// Get the next task, grabbing a batch of submitted tasks if the local queue is empty
+		// or if they have a higher priority
+		worker.grabUrgent()
+		workerTask := worker.queue.PopBottom()
+		if workerTask == nil && worker.grab() > 0 {
+			workerTask = worker.queue.PopBottom()
+		}
 		if workerTask != nil {
-			runnable, ok := workerTask.(Runnable)
-			if ok {
-				// Run the task
-				runnable.Run()
-			}
+			// Run the task
+			worker.stats.working()
+			worker.stats.runTask(workerTask, worker.forkJoin)
 		}
 
 		// Rebalancing is only done if there is more than one worker
-		// and at random (i.e. 1/n chance where n is the size of the queue)
-		queueSize := worker.context.queues[worker.id].Size()
-		if worker.context.capacity > 1 && queueSize == worker.randGen.Intn(queueSize+1) {
+		// and when the balance policy triggers it (at random by default)
+		iteration++
+		queueSize := worker.queue.Size()
+		worker.stats.observeQueue(queueSize)
+		if worker.capacity > 1 && worker.context.balancePolicy.Trigger(iteration, queueSize, worker.randGen) {
 			// Balance the queues
 			worker.balance()
 		}
+
+		// Idle if no work was found (balancing may have moved tasks into the local queue)
+		if workerTask != nil || !worker.queue.IsEmpty() {
+			worker.idler.reset()
+		} else {
+			worker.stats.idling()
+			worker.idler.idle(epoch, worker.isWorkPoolEmpty)
+		}
 	}
 
-	// Worker is done
-	worker.context.wg.Done()
+	// Hand the remaining tasks over if the worker was retired
+	worker.exit()
 }
 
 // NewWorkBalancingExecutor returns an ExecutorService that is implemented using the work-balancing algorithm.
@@ -149,80 +117,24 @@ func (worker *workerWB) work() {
 // balancing. Remember, if two local queues are to be balanced the
 // difference in the sizes of the queues must be greater than or equal to
 // thresholdBalance. You must use this parameter in your implementation.
-func NewWorkBalancingExecutor(capacity, thresholdQueue, thresholdBalance int) ExecutorService {
-	// Create capacity queues
-	queues := []DEQueue{}
-	for i := 0; i < capacity; i++ {
-		queues = append(queues, NewUnBoundedDEQueue())
-	}
+// @param options - Optional settings of the executor (e.g. WithDEQueue)
+func NewWorkBalancingExecutor(capacity, thresholdQueue, thresholdBalance int, options ...Option) ExecutorService {
+	// Apply the optional settings
+	config := newOptions(options)
 
 	// Create shared context
 	context := &sharedContextWB{
-		capacity:         capacity,
-		thresholdQueue:   thresholdQueue,
+		poolContext:      newPoolContext(capacity, thresholdQueue, config),
 		thresholdBalance: thresholdBalance,
-		queues:           queues,
-		wg:               &sync.WaitGroup{},
-	}
-
-	// Create capacity workers
-	workers := []*workerWB{}
-	for i := 0; i < capacity; i++ {
-		workers = append(workers, NewWorkerWB(i, context))
-	}
-
-	// Spawn worker routines
-	for _, worker := range workers {
-		// Increment wait group (Decrement inside the Run() method)
-		context.wg.Add(1)
-		go worker.work()
+		balancePolicy:    config.balancePolicy,
 	}
 
-	// Create service
+	// Create service, it creates and spawns capacity workers
 	service := &balancer{
-		workers:         workers,
-		done:            false,
-		prevDistributee: capacity - 1,
-		context:         context,
+		workerPool: newWorkerPool(capacity, context.poolContext, config, func(id int) (*poolWorker, func()) {
+			worker := NewWorkerWB(id, context)
+			return worker.poolWorker, worker.work
+		}),
 	}
-
 	return service
 }
-
-// Get the next worker idx to distribute work to
-func (service *balancer) nextDistributee() int {
-	// Get next distributee and update prevDistributee
-	service.prevDistributee = (service.prevDistributee + 1) % service.context.capacity
-	return service.prevDistributee
-}
-
-// Submit a task to the executor
-func (service *balancer) Submit(task interface{}) Future {
-	// Check if service is done
-	if service.done {
-		return nil
-	}
-
-	// Get next distributee
-	distributee := service.nextDistributee()
-
-	// Add task to distributee's queue
-	service.context.queues[distributee].PushBottom(task)
-
-	// Type assertion
-	return task.(Future)
-}
-
-// Shutdown the executor
-func (service *balancer) Shutdown() {
-	// Indicate the service is done for all workers
-	for _, worker := range service.workers {
-		worker.workRemaining = false
-	}
-
-	// Indicate the service is done
-	service.done = true
-
-	// Wait for all workers to finish
-	service.context.wg.Wait()
-}
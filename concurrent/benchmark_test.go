@@ -0,0 +1,171 @@
+package concurrent
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Owner pushing and popping its own tasks without any thief
+func BenchmarkDEQueuePushPop(b *testing.B) {
+	for _, implementation := range dequeImplementations {
+		b.Run(implementation.name, func(b *testing.B) {
+			queue := implementation.newDEQueue()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				queue.PushBottom(i)
+				queue.PopBottom()
+			}
+		})
+	}
+}
+
+// Thieves stealing from a deque filled beforehand
+func BenchmarkDEQueueSteal(b *testing.B) {
+	for _, implementation := range dequeImplementations {
+		b.Run(implementation.name, func(b *testing.B) {
+			queue := implementation.newDEQueue()
+			for i := 0; i < b.N; i++ {
+				queue.PushBottom(i)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					queue.PopTop()
+				}
+			})
+		})
+	}
+}
+
+// Owner pushing tasks and popping some of them back while thieves steal the others, the time
+// per operation is the time per task
+func BenchmarkDEQueueOwnerThieves(b *testing.B) {
+	for _, implementation := range dequeImplementations {
+		for _, thieves := range []int{1, 3, 7} {
+			b.Run(fmt.Sprintf("%s/thieves=%d", implementation.name, thieves), func(b *testing.B) {
+				queue := implementation.newDEQueue()
+				remaining := int64(b.N)
+
+				wg := &sync.WaitGroup{}
+				wg.Add(thieves)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < thieves; i++ {
+					go func() {
+						defer wg.Done()
+						for atomic.LoadInt64(&remaining) > 0 {
+							if queue.PopTop() != nil {
+								atomic.AddInt64(&remaining, -1)
+							} else {
+								runtime.Gosched()
+							}
+						}
+					}()
+				}
+
+				// Pop one task back for every two pushed tasks
+				for i := 0; i < b.N; i++ {
+					queue.PushBottom(i)
+					if i%2 == 1 && queue.PopBottom() != nil {
+						atomic.AddInt64(&remaining, -1)
+					}
+				}
+				for atomic.LoadInt64(&remaining) > 0 {
+					if queue.PopBottom() != nil {
+						atomic.AddInt64(&remaining, -1)
+					}
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
+
+// Synthetic task spinning for the given number of iterations, it records when it started
+type spinTask struct {
+	iterations int
+	started    time.Time
+	result     int // Keeps the loop from being optimized away
+}
+
+func (task *spinTask) Run() {
+	task.started = time.Now()
+	result := 1
+	for i := 0; i < task.iterations; i++ {
+		result = result*31 + i
+	}
+	task.result = result
+}
+
+// Synthetic workloads, each returns the number of iterations of the i-th task
+var workloads = []struct {
+	name       string
+	iterations func(i int) int
+}{
+	{"tiny", func(i int) int { return 0 }},
+	{"uniform", func(i int) int { return 2000 }},
+	// Most tasks are small and a few are a hundred times larger
+	{"skewed", func(i int) int {
+		if i%32 == 0 {
+			return 50000
+		}
+		return 500
+	}},
+}
+
+// Number of workers the executors are benchmarked with
+var benchmarkThreads = []int{1, 2, 4, 8}
+
+// Runs the benchmark for every executor, workload and number of workers
+func forEachExecutor(b *testing.B, benchmark func(b *testing.B, executor ExecutorService, iterations func(i int) int)) {
+	for _, implementation := range executorImplementations {
+		for _, workload := range workloads {
+			for _, threads := range benchmarkThreads {
+				implementation, workload, threads := implementation, workload, threads
+				b.Run(fmt.Sprintf("%s/%s/threads=%d", implementation.name, workload.name, threads), func(b *testing.B) {
+					benchmark(b, implementation.newExecutor(threads), workload.iterations)
+				})
+			}
+		}
+	}
+}
+
+// Submitting b.N tasks and waiting for all of them, the time per operation is the time per task
+func BenchmarkExecutorThroughput(b *testing.B) {
+	forEachExecutor(b, func(b *testing.B, executor ExecutorService, iterations func(i int) int) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			executor.Submit(&spinTask{iterations: iterations(i)})
+		}
+		executor.Shutdown()
+	})
+}
+
+// Time between the submission of a task and the moment a worker starts running it while the
+// executor is otherwise idle, each task is waited for before the next one is submitted
+func BenchmarkExecutorLatency(b *testing.B) {
+	forEachExecutor(b, func(b *testing.B, executor ExecutorService, iterations func(i int) int) {
+		latencies := make([]time.Duration, b.N)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			task := &spinTask{iterations: iterations(i)}
+			submitted := time.Now()
+			executor.Submit(task).Get()
+			latencies[i] = task.started.Sub(submitted)
+		}
+		executor.Shutdown()
+		b.StopTimer()
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		b.ReportMetric(float64(latencies[b.N/2].Nanoseconds()), "p50-ns")
+		b.ReportMetric(float64(latencies[b.N*99/100].Nanoseconds()), "p99-ns")
+	})
+}
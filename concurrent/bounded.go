@@ -0,0 +1,79 @@
+package concurrent
+
+import (
+	"context"
+)
+
+// bound limits the number of pending tasks (submitted but not done) of an executor, a nil bound
+// does not limit anything
+type bound struct {
+	slots chan struct{} // Holds one element per pending task
+}
+
+// Returns a bound of limit pending tasks (nil if limit < 1)
+func newBound(limit int) *bound {
+	if limit < 1 {
+		return nil
+	}
+	return &bound{slots: make(chan struct{}, limit)}
+}
+
+// Wait for a slot, returns ctx.Err() if ctx is done or ErrRejected if the executor is shut down first
+func (b *bound) acquire(ctx context.Context, l *lifecycle) error {
+	if b == nil {
+		return nil
+	}
+
+	// Prefer a free slot over a context that is already done
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.stopping:
+		return ErrRejected
+	}
+}
+
+// Take a slot without waiting, returns whether there was one
+func (b *bound) tryAcquire() bool {
+	if b == nil {
+		return true
+	}
+
+	select {
+	case b.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Give the slot back once the job is done, it must be called for every acquired slot
+func (b *bound) track(job *future) {
+	if b == nil {
+		return
+	}
+	job.onDone(b.release)
+}
+
+// Give a slot back
+func (b *bound) release() {
+	if b == nil {
+		return
+	}
+	<-b.slots
+}
+
+// Returns the future of a task that was not submitted because of err
+func failedFuture(task interface{}, err error) *future {
+	f := newFuture(context.Background(), task)
+	f.cancel(err)
+	return f
+}
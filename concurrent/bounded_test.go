@@ -0,0 +1,99 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// The executors with a bound of 2 pending tasks, each is created with a single worker
+var boundedImplementations = []struct {
+	name        string
+	newExecutor func() ExecutorService
+}{
+	{"ws", func() ExecutorService { return NewWorkStealingExecutor(1, 1, WithMaxPending(2)) }},
+	{"wb", func() ExecutorService { return NewWorkBalancingExecutor(1, 1, 1, WithMaxPending(2)) }},
+	{"wsh", func() ExecutorService { return NewWorkSharingExecutor(1, WithMaxPending(2)) }},
+}
+
+func TestBoundedSubmit(t *testing.T) {
+	for _, implementation := range boundedImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			executor := implementation.newExecutor()
+			defer executor.Shutdown()
+			bounded := executor.(BoundedExecutorService)
+
+			// The slots are taken until the tasks are done
+			blocker := &blockingTask{release: make(chan struct{})}
+			first, ok := bounded.TrySubmit(blocker)
+			if !ok {
+				t.Fatal("first task was not submitted")
+			}
+			second, ok := bounded.TrySubmit(blocker)
+			if !ok {
+				t.Fatal("second task was not submitted")
+			}
+			if _, ok := bounded.TrySubmit(blocker); ok {
+				t.Fatal("third task was submitted past the bound")
+			}
+
+			// A submission waiting for a slot gives up with its context
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if err := bounded.(ContextExecutorService).SubmitContext(ctx, blocker).(ErrFuture).Err(); err != context.DeadlineExceeded {
+				t.Fatalf("submission past the bound failed with %v", err)
+			}
+
+			// Submit waits for a slot
+			submitted := make(chan Future)
+			go func() {
+				submitted <- executor.Submit(&squareTask{n: 3})
+			}()
+			select {
+			case <-submitted:
+				t.Fatal("Submit did not wait for a slot")
+			case <-time.After(10 * time.Millisecond):
+			}
+			close(blocker.release)
+			if value := (<-submitted).Get(); value != 9 {
+				t.Fatalf("task returned %v, expected 9", value)
+			}
+			for _, future := range []Future{first, second} {
+				if err := future.(ErrFuture).Err(); err != nil {
+					t.Fatalf("task failed with %v", err)
+				}
+			}
+		})
+	}
+}
+
+// A submission waiting for a slot is rejected once the executor is shut down
+func TestBoundedShutdown(t *testing.T) {
+	for _, implementation := range boundedImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			executor := implementation.newExecutor()
+			extended := executor.(ExtendedExecutorService)
+
+			blocker := &blockingTask{release: make(chan struct{})}
+			executor.Submit(blocker)
+			executor.Submit(blocker)
+			submitted := make(chan Future)
+			go func() {
+				submitted <- executor.Submit(blocker)
+			}()
+			time.Sleep(10 * time.Millisecond)
+
+			// Shutdown waits for the pending tasks while the blocked submission is rejected
+			go executor.Shutdown()
+			if err := (<-submitted).(ErrFuture).Err(); err != ErrRejected {
+				t.Fatalf("blocked submission failed with %v", err)
+			}
+			close(blocker.release)
+			if !extended.AwaitTermination(time.Second) {
+				t.Fatal("executor did not terminate")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package concurrent
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Initial number of slots in the circular array of a ChaseLevDEQueue (must be a power of two)
+const chaseLevInitialSize = 32
+
+// Slot of the circular array, tasks are boxed so that they can be read and written atomically
+type slot struct {
+	task Task
+}
+
+// Growable circular array used by the Chase-Lev deque
+type circularArray struct {
+	mask  int64
+	slots []unsafe.Pointer
+}
+
+func newCircularArray(size int64) *circularArray {
+	return &circularArray{
+		mask:  size - 1,
+		slots: make([]unsafe.Pointer, size),
+	}
+}
+
+// Number of slots in the array
+func (a *circularArray) size() int64 {
+	return a.mask + 1
+}
+
+// Get the task stored at index i (nil if the slot was never written, e.g. a thief reading an index
+// that was already stolen from an array grown meanwhile)
+func (a *circularArray) get(i int64) Task {
+	boxed := (*slot)(atomic.LoadPointer(&a.slots[i&a.mask]))
+	if boxed == nil {
+		return nil
+	}
+	return boxed.task
+}
+
+// Store a task at index i
+func (a *circularArray) put(i int64, task Task) {
+	atomic.StorePointer(&a.slots[i&a.mask], unsafe.Pointer(&slot{task: task}))
+}
+
+// Returns a copy of the array with twice the number of slots containing the tasks in [top, bottom)
+func (a *circularArray) grow(bottom, top int64) *circularArray {
+	grown := newCircularArray(2 * a.size())
+	for i := top; i < bottom; i++ {
+		grown.put(i, a.get(i))
+	}
+	return grown
+}
+
+// ChaseLevDEQueue is a lock-free work-stealing deque backed by a growable circular array
+// (Chase and Lev, "Dynamic Circular Work-Stealing Deque", SPAA 2005).
+// PushBottom and PopBottom may only be called by the goroutine that owns the queue while
+// PopTop, IsEmpty and Size may be called by any goroutine. Old arrays are reclaimed by
+// the garbage collector once no thief is reading from them anymore.
+type ChaseLevDEQueue struct {
+	top    int64          // Index of the oldest task, advanced by thieves and by the owner for the last task
+	bottom int64          // Index of the next free slot, only written by the owner
+	array  unsafe.Pointer // Current *circularArray
+}
+
+// Visualized representation of the queue
+// (top) oldest task -> ... -> newest task (bottom)
+
+// NewChaseLevDEQueue returns an empty ChaseLevDEQueue
+func NewChaseLevDEQueue() DEQueue {
+	return &ChaseLevDEQueue{
+		top:    0,
+		bottom: 0,
+		array:  unsafe.Pointer(newCircularArray(chaseLevInitialSize)),
+	}
+}
+
+// Load the current circular array
+func (q *ChaseLevDEQueue) loadArray() *circularArray {
+	return (*circularArray)(atomic.LoadPointer(&q.array))
+}
+
+// PushBottom adds a task to the bottom of the queue (owner only)
+func (q *ChaseLevDEQueue) PushBottom(task Task) {
+	bottom := atomic.LoadInt64(&q.bottom)
+	top := atomic.LoadInt64(&q.top)
+	array := q.loadArray()
+
+	// Grow the array if it is full
+	if bottom-top >= array.size()-1 {
+		array = array.grow(bottom, top)
+		atomic.StorePointer(&q.array, unsafe.Pointer(array))
+	}
+
+	// Store the task before publishing it to the thieves
+	array.put(bottom, task)
+	atomic.StoreInt64(&q.bottom, bottom+1)
+}
+
+// PopBottom removes a task from the bottom of the queue (owner only)
+func (q *ChaseLevDEQueue) PopBottom() Task {
+	// Reserve the bottom task before looking at the top
+	bottom := atomic.LoadInt64(&q.bottom) - 1
+	array := q.loadArray()
+	atomic.StoreInt64(&q.bottom, bottom)
+	top := atomic.LoadInt64(&q.top)
+
+	// Check if the queue is empty
+	if bottom < top {
+		atomic.StoreInt64(&q.bottom, top)
+		return nil
+	}
+
+	task := array.get(bottom)
+
+	// No thief can reach the task if more than one task is left
+	if bottom > top {
+		return task
+	}
+
+	// Race the thieves for the last task
+	if !atomic.CompareAndSwapInt64(&q.top, top, top+1) {
+		task = nil
+	}
+	atomic.StoreInt64(&q.bottom, top+1)
+	return task
+}
+
+// PopTop removes a task from the top of the queue
+func (q *ChaseLevDEQueue) PopTop() Task {
+	for {
+		top := atomic.LoadInt64(&q.top)
+		bottom := atomic.LoadInt64(&q.bottom)
+
+		// Check if the queue is empty
+		if bottom <= top {
+			return nil
+		}
+
+		if task, ok := q.steal(top, bottom); ok {
+			return task
+		}
+		// Lost the race to another thief or the owner, try again
+	}
+}
+
+// Claim the task at index top observed along with bottom, returns false if another thief or the
+// owner claimed it first. The indices may be stale by then (e.g. the array may have grown), the
+// CAS on top decides whether the task that was read is still the top task.
+func (q *ChaseLevDEQueue) steal(top, bottom int64) (Task, bool) {
+	// Read the task before claiming it since the owner may overwrite the slot afterwards
+	task := q.loadArray().get(top)
+	if !atomic.CompareAndSwapInt64(&q.top, top, top+1) {
+		return nil, false
+	}
+	return task, true
+}
+
+// IsEmpty returns whether the queue is empty
+func (q *ChaseLevDEQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Size returns the size of the queue
+func (q *ChaseLevDEQueue) Size() int {
+	size := atomic.LoadInt64(&q.bottom) - atomic.LoadInt64(&q.top)
+	// The owner temporarily decrements bottom below top while popping from an empty queue
+	if size < 0 {
+		return 0
+	}
+	return int(size)
+}
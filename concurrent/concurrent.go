@@ -1,5 +1,11 @@
 package concurrent
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
 /**** YOU CANNOT MODIFY ANY OF THE FOLLOWING INTERFACES ********/
 
 // Runnable represents a task that does not return a value.
@@ -29,3 +35,127 @@ type ExecutorService interface {
 }
 
 /******** DO NOT MODIFY ANY OF THE ABOVE INTERFACES *********************/
+
+// ContextRunnable is a Runnable that can observe the context it was submitted with while running.
+type ContextRunnable interface {
+	Runnable
+	RunContext(ctx context.Context) error // Starts the execution of the task with its context, returns ctx.Err() if it stopped early because ctx is done
+}
+
+// ContextCallable is a Callable that can observe the context it was submitted with while running.
+type ContextCallable interface {
+	Callable
+	CallContext(ctx context.Context) interface{} // Starts the execution of the task with its context, returns ctx.Err() if it stopped early because ctx is done
+}
+
+// ForkJoin lets a running ForkJoinTask split its work into subtasks. Its methods must only be called from the goroutine running the task.
+type ForkJoin interface {
+	// Fork pushes a subtask onto the local queue of the worker running the task, where idle workers can steal it, and returns a Future (a SelectableFuture) representing it. The subtask inherits the context and the priority of the task.
+	Fork(task interface{}) Future
+	// Join waits for a Future to complete and returns the value of its Get method. Instead of blocking, the worker runs other pending tasks while waiting (its own first, most likely the forked subtask itself).
+	Join(f Future) interface{}
+	// Context returns the context the task was submitted with.
+	Context() context.Context
+}
+
+// ForkJoinTask is a task that can fork subtasks onto the queue of the worker running it (e.g. to split its work recursively). Executors run Compute instead of Run or Call.
+type ForkJoinTask interface {
+	Compute(fj ForkJoin) interface{} // Starts the execution of the task, the returned value is the value of its Future (fj.Context().Err() if it stopped early because its context is done)
+}
+
+// ErrFuture is a Future whose task may not complete (e.g. because it was cancelled or panicked).
+type ErrFuture interface {
+	Future
+	// Err waits (if necessary) for the task to complete and returns the reason it did not complete (e.g. the error of its context or a *PanicError) or nil if it did.
+	Err() error
+}
+
+// ErrTimeout is returned by GetTimeout when the task did not complete in time.
+var ErrTimeout = errors.New("concurrent: timed out waiting for the task to complete")
+
+// SelectableFuture is a Future that can be waited on in a select statement or with a timeout. Every Future returned by the executors of this package is a SelectableFuture.
+type SelectableFuture interface {
+	ErrFuture
+	// Done returns a channel that is closed once the task has completed (successfully or not).
+	Done() <-chan struct{}
+	// GetTimeout waits at most timeout for the task to complete and returns the same value as Get along with the error returned by Err, or ErrTimeout if the task did not complete in time.
+	GetTimeout(timeout time.Duration) (interface{}, error)
+}
+
+// ContextExecutorService is an ExecutorService that can bind tasks to a context.
+type ContextExecutorService interface {
+	ExecutorService
+
+	// SubmitContext submits a task bound to ctx for execution and returns a Future (a SelectableFuture) representing that task. If ctx is done before the task starts, the task is skipped and its Future resolves with a nil value and ctx.Err(). A task implementing ContextRunnable or ContextCallable is given ctx so that it can stop early once ctx is done, its Future then resolves with a nil value and ctx.Err() if the task returned it (a task that ran to completion succeeds even if ctx is done by then). Per-task timeouts can be set using context.WithTimeout. Submit and SubmitContext are safe to call from multiple goroutines, also during a shutdown: a task submitted once the shutdown has started is rejected and its Future resolves with a nil value and ErrRejected.
+	SubmitContext(ctx context.Context, task interface{}) Future
+}
+
+// StatsExecutorService is an ExecutorService that keeps statistics about its workers.
+type StatsExecutorService interface {
+	ExecutorService
+	// Stats returns a snapshot of the statistics of the workers, it is safe to call at any time.
+	Stats() Stats
+}
+
+// ExtendedExecutorService is an ExecutorService whose shutdown can be forced and waited on with a timeout.
+type ExtendedExecutorService interface {
+	ExecutorService
+	// ShutdownNow initiates a shutdown like Shutdown but removes the tasks that have not started yet (including the tasks waiting for their dependencies) and returns them without waiting for the running tasks. The Futures of the removed tasks resolve with ErrShutdown.
+	ShutdownNow() []interface{}
+	// AwaitTermination waits at most timeout for every worker of the service to terminate after a shutdown and returns whether they did.
+	AwaitTermination(timeout time.Duration) bool
+	// IsShutdown returns whether a shutdown of the service was initiated.
+	IsShutdown() bool
+	// IsTerminated returns whether every worker of the service terminated after a shutdown.
+	IsTerminated() bool
+}
+
+// PriorityExecutorService is a ContextExecutorService whose tasks carry a priority.
+type PriorityExecutorService interface {
+	ContextExecutorService
+	// SubmitPriority submits a task bound to ctx with the given priority for execution and returns a Future representing that task. Workers run the tasks of the highest priority they can find first, among the submitted tasks and the tasks queued by the other workers (a busy worker steals a task only if it is more urgent than its own). Tasks submitted through Submit and SubmitContext have DefaultPriority. The priority is clamped to the number of priority levels of the service.
+	SubmitPriority(ctx context.Context, task interface{}, priority Priority) Future
+}
+
+// DependencyExecutorService is a ContextExecutorService that can run tasks once other tasks have completed.
+type DependencyExecutorService interface {
+	ContextExecutorService
+	// SubmitAfter submits a task that is only queued once every Future in deps has completed and returns a Future representing that task. No worker is blocked while the task waits. If a dependency does not complete (see ErrFuture), the task is skipped and its Future resolves with a nil value and a *DependencyError, which in turn skips the tasks depending on it.
+	SubmitAfter(task interface{}, deps ...Future) Future
+	// SubmitGraph submits every task of the graph bound to ctx after its dependencies (as with SubmitAfter) and returns their Futures indexed like the tasks of the graph. Nothing is submitted if the graph contains a cycle (ErrCycle) or a dependency that does not exist.
+	SubmitGraph(ctx context.Context, graph *TaskGraph) ([]Future, error)
+}
+
+// ResizableExecutorService is an ExecutorService whose number of workers can change while it runs (see also WithAutoScaler).
+type ResizableExecutorService interface {
+	ExecutorService
+	// Resize changes the number of workers to n (at least one). New workers start with empty queues, retired workers finish their current task and hand their queued tasks over to the remaining workers. Stats only reports the current workers. Resize has no effect once a shutdown was initiated.
+	Resize(n int)
+	// Workers returns the current number of workers.
+	Workers() int
+}
+
+// BoundedExecutorService is a ContextExecutorService that can bound the number of its pending tasks (see WithMaxPending) to apply backpressure to the goroutines submitting tasks.
+type BoundedExecutorService interface {
+	ContextExecutorService
+	// TrySubmit submits a task like Submit unless the number of pending tasks (submitted but not done) has reached the bound, in which case it returns false and a nil Future instead of blocking. Submit, SubmitContext and SubmitPriority block until a pending task is done, SubmitContext until ctx is done at the latest (the Future then resolves with ctx.Err()). A submission blocked when the shutdown starts is rejected. Tasks submitted through SubmitAfter, SubmitGraph and ForkJoin.Fork are not bounded. A task submitting to its own executor may block forever once the bound is reached.
+	TrySubmit(task interface{}) (Future, bool)
+}
+
+// ScheduledFuture is the Future of a task scheduled through a ScheduledExecutorService.
+type ScheduledFuture interface {
+	SelectableFuture
+	// Cancel removes the task from the schedule and returns whether it was cancelled before running to completion. A run already handed over to the executor is skipped unless it has started (a run in progress is not interrupted). The Future resolves with ErrCancelled.
+	Cancel() bool
+	// Delay returns the time left until the task is due next, 0 once it is no longer scheduled.
+	Delay() time.Duration
+}
+
+// ScheduledExecutorService is an ExecutorService that can run tasks after a delay or periodically. The tasks are handed over to an executor once they are due, a single goroutine keeps track of every scheduled task.
+type ScheduledExecutorService interface {
+	ExecutorService
+	// Schedule runs a task once after the delay and returns a Future representing it, which resolves with the result of the task.
+	Schedule(task interface{}, delay time.Duration) ScheduledFuture
+	// ScheduleAtFixedRate runs a task after the initial delay and then every period (which must be positive) until it is cancelled, a run fails (e.g. panics) or the service is shut down. A run starts once the previous run is done, right away if the previous run took longer than the period. The returned Future resolves with the reason the task stopped running (ErrCancelled, the error of the run or ErrShutdown).
+	ScheduleAtFixedRate(task interface{}, initialDelay, period time.Duration) ScheduledFuture
+}
@@ -0,0 +1,192 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrCycle is the error returned by SubmitGraph when the tasks of a TaskGraph depend on each other in a cycle
+var ErrCycle = errors.New("concurrent: task graph contains a cycle")
+
+// DependencyError is the error of a Future whose task was skipped because one of its dependencies
+// did not complete
+type DependencyError struct {
+	Err error // The error of the dependency that did not complete
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("concurrent: dependency did not complete: %v", e.Err)
+}
+
+// Unwrap returns the error of the dependency
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the error of a task whose dependency failed with err
+func dependencyError(err error) error {
+	// Keep the error of the dependency that failed first along a chain of dependencies
+	if _, ok := err.(*DependencyError); ok {
+		return err
+	}
+	return &DependencyError{Err: err}
+}
+
+// Call back once the Future is done with the error of its task (nil if it completed)
+func whenDone(dep Future, callback func(err error)) {
+	if f, ok := dep.(*future); ok {
+		f.onDone(func() { callback(f.err) })
+		return
+	}
+
+	// Futures of other implementations can only be waited on in their own goroutine
+	go func() {
+		dep.Get()
+		var err error
+		if errFuture, ok := dep.(ErrFuture); ok {
+			err = errFuture.Err()
+		}
+		callback(err)
+	}()
+}
+
+// Push the job once all of its dependencies are done, or resolve its future with a DependencyError
+// as soon as one of them fails. The job counts as a submission until then so that the workers
+// do not exit while it waits (i.e. Shutdown waits for it), and ShutdownNow removes it.
+func submitAfter(l *lifecycle, push func(job *future), job *future, deps []Future) Future {
+	if !l.beginSubmit() {
+		job.cancel(ErrRejected)
+		return job
+	}
+	l.addWaiting(job)
+
+	// The job is only released once all dependencies are registered (hence the extra one)
+	pending := int64(len(deps)) + 1
+	settled := int32(0)
+	settle := func() {
+		if atomic.CompareAndSwapInt32(&settled, 0, 1) {
+			l.removeWaiting(job)
+			l.release()
+		}
+	}
+	release := func(err error) {
+		if err != nil {
+			job.cancel(dependencyError(err))
+			settle()
+		}
+		if atomic.AddInt64(&pending, -1) == 0 {
+			// The job may have been cancelled by a failed dependency or its context
+			if atomic.LoadInt32(&job.state) == futurePending {
+				push(job)
+			}
+			settle()
+		}
+	}
+
+	for _, dep := range deps {
+		// A nil Future (i.e. a rejected task of an older executor) has nothing to wait for
+		if dep == nil {
+			release(nil)
+			continue
+		}
+		whenDone(dep, release)
+	}
+	release(nil)
+	return job
+}
+
+// TaskGraph is a set of tasks and the dependencies between them, submitted to an executor at once
+// through SubmitGraph. Tasks are identified by the index returned by Add.
+type TaskGraph struct {
+	tasks        []interface{}
+	dependencies [][]int // Indexed by task, the tasks it runs after
+}
+
+// NewTaskGraph returns an empty TaskGraph
+func NewTaskGraph() *TaskGraph {
+	return &TaskGraph{
+		tasks:        []interface{}{},
+		dependencies: [][]int{},
+	}
+}
+
+// Add adds a task that runs after the given tasks and returns its index
+func (graph *TaskGraph) Add(task interface{}, dependencies ...int) int {
+	graph.tasks = append(graph.tasks, task)
+	graph.dependencies = append(graph.dependencies, append([]int{}, dependencies...))
+	return len(graph.tasks) - 1
+}
+
+// DependsOn makes the task run after the dependency
+func (graph *TaskGraph) DependsOn(task, dependency int) {
+	graph.dependencies[task] = append(graph.dependencies[task], dependency)
+}
+
+// Len returns the number of tasks in the graph
+func (graph *TaskGraph) Len() int {
+	return len(graph.tasks)
+}
+
+// Returns the tasks in an order where every task comes after its dependencies,
+// or an error if a dependency does not exist or the dependencies contain a cycle
+func (graph *TaskGraph) order() ([]int, error) {
+	// Count the dependencies of each task and collect the dependents of each task
+	remaining := make([]int, len(graph.tasks))
+	dependents := make([][]int, len(graph.tasks))
+	for task, dependencies := range graph.dependencies {
+		for _, dependency := range dependencies {
+			if dependency < 0 || dependency >= len(graph.tasks) {
+				return nil, fmt.Errorf("concurrent: dependency %d of task %d does not exist", dependency, task)
+			}
+			remaining[task]++
+			dependents[dependency] = append(dependents[dependency], task)
+		}
+	}
+
+	// Kahn's algorithm, starting with the tasks without dependencies
+	order := []int{}
+	for task := range graph.tasks {
+		if remaining[task] == 0 {
+			order = append(order, task)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, dependent := range dependents[order[i]] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				order = append(order, dependent)
+			}
+		}
+	}
+
+	// Tasks that never ran out of dependencies are part of (or depend on) a cycle
+	if len(order) < len(graph.tasks) {
+		for task := range graph.tasks {
+			if remaining[task] > 0 {
+				return nil, fmt.Errorf("%w (task %d)", ErrCycle, task)
+			}
+		}
+	}
+	return order, nil
+}
+
+// Submit every task of the graph bound to ctx after its dependencies, returns the futures indexed
+// by task. Nothing is submitted if the graph is invalid.
+func submitGraph(l *lifecycle, push func(job *future), ctx context.Context, graph *TaskGraph) ([]Future, error) {
+	order, err := graph.order()
+	if err != nil {
+		return nil, err
+	}
+
+	futures := make([]Future, len(graph.tasks))
+	for _, task := range order {
+		deps := []Future{}
+		for _, dependency := range graph.dependencies[task] {
+			deps = append(deps, futures[dependency])
+		}
+		futures[task] = submitAfter(l, push, newFuture(ctx, graph.tasks[task]), deps)
+	}
+	return futures, nil
+}
@@ -0,0 +1,132 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Runnable recording when it ran on a clock shared by the tasks
+type stampTask struct {
+	clock *int64
+	at    int64
+}
+
+func (task *stampTask) Run() {
+	atomic.StoreInt64(&task.at, atomic.AddInt64(task.clock, 1))
+}
+
+func (task *stampTask) ranAt() int64 {
+	return atomic.LoadInt64(&task.at)
+}
+
+func TestSubmitAfter(t *testing.T) {
+	forEachExecutorService(t, 3, func(t *testing.T, executor ExecutorService) {
+		dependent := executor.(DependencyExecutorService)
+		clock := int64(0)
+
+		first, second, third := &stampTask{clock: &clock}, &stampTask{clock: &clock}, &stampTask{clock: &clock}
+		firstFuture := executor.Submit(first)
+		secondFuture := executor.Submit(second)
+		thirdFuture := dependent.SubmitAfter(third, firstFuture, secondFuture)
+		if err := thirdFuture.(ErrFuture).Err(); err != nil {
+			t.Fatalf("dependent task failed with %v", err)
+		}
+		if third.ranAt() <= first.ranAt() || third.ranAt() <= second.ranAt() {
+			t.Fatalf("dependent task ran at %d before its dependencies (%d and %d)", third.ranAt(), first.ranAt(), second.ranAt())
+		}
+
+		// A failed dependency skips the dependent tasks transitively and keeps the original error
+		failed := executor.Submit(&panickingTask{message: "boom"})
+		skipped := dependent.SubmitAfter(&stampTask{clock: &clock}, firstFuture, failed)
+		skippedTransitively := dependent.SubmitAfter(&stampTask{clock: &clock}, skipped)
+		var dependencyErr *DependencyError
+		if err := skippedTransitively.(ErrFuture).Err(); !errors.As(err, &dependencyErr) {
+			t.Fatalf("transitively skipped task failed with %v", err)
+		}
+		var panicErr *PanicError
+		if err := skipped.(ErrFuture).Err(); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+			t.Fatalf("skipped task failed with %v", err)
+		}
+	})
+}
+
+func TestSubmitGraph(t *testing.T) {
+	forEachExecutorService(t, 3, func(t *testing.T, executor ExecutorService) {
+		dependent := executor.(DependencyExecutorService)
+		clock := int64(0)
+
+		// A diamond: first -> (left, right) -> last
+		tasks := []*stampTask{}
+		for i := 0; i < 4; i++ {
+			tasks = append(tasks, &stampTask{clock: &clock})
+		}
+		graph := NewTaskGraph()
+		first := graph.Add(tasks[0])
+		left := graph.Add(tasks[1], first)
+		right := graph.Add(tasks[2], first)
+		last := graph.Add(tasks[3], left, right)
+		futures, err := dependent.SubmitGraph(context.Background(), graph)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(futures) != graph.Len() {
+			t.Fatalf("%d futures for %d tasks", len(futures), graph.Len())
+		}
+		for _, future := range futures {
+			if err := future.(ErrFuture).Err(); err != nil {
+				t.Fatalf("task failed with %v", err)
+			}
+		}
+		for _, edge := range [][2]int{{first, left}, {first, right}, {left, last}, {right, last}} {
+			if tasks[edge[1]].ranAt() <= tasks[edge[0]].ranAt() {
+				t.Fatalf("task %d ran before its dependency %d", edge[1], edge[0])
+			}
+		}
+
+		// A graph with a cycle is rejected as a whole
+		cyclic := NewTaskGraph()
+		a := cyclic.Add(&stampTask{clock: &clock})
+		b := cyclic.Add(&stampTask{clock: &clock}, a)
+		cyclic.DependsOn(a, b)
+		if _, err := dependent.SubmitGraph(context.Background(), cyclic); !errors.Is(err, ErrCycle) {
+			t.Fatalf("cyclic graph submitted with %v", err)
+		}
+	})
+}
+
+// ShutdownNow removes the tasks waiting for their dependencies along with the queued tasks
+func TestShutdownNowDependents(t *testing.T) {
+	forEachExecutorService(t, 1, func(t *testing.T, executor ExecutorService) {
+		dependent := executor.(DependencyExecutorService)
+		extended := executor.(ExtendedExecutorService)
+		clock := int64(0)
+
+		blocker := &blockingTask{release: make(chan struct{})}
+		blockerFuture := executor.Submit(blocker)
+		first, second := &stampTask{clock: &clock}, &stampTask{clock: &clock}
+		firstFuture := dependent.SubmitAfter(first, blockerFuture)
+		secondFuture := dependent.SubmitAfter(second, firstFuture)
+
+		drained := extended.ShutdownNow()
+		close(blocker.release)
+		if !extended.AwaitTermination(time.Second) {
+			t.Fatal("executor did not terminate")
+		}
+
+		// The waiting tasks come first, followed by the blocker if it had not started yet
+		if len(drained) < 2 || drained[0] != first || drained[1] != second {
+			t.Fatalf("drained %v, expected the dependent tasks in submission order", drained)
+		}
+		for _, future := range []Future{firstFuture, secondFuture} {
+			if err := future.(ErrFuture).Err(); err != ErrShutdown {
+				t.Fatalf("waiting task failed with %v", err)
+			}
+		}
+		if first.ranAt() != 0 || second.ranAt() != 0 {
+			t.Fatal("waiting task ran after ShutdownNow")
+		}
+	})
+}
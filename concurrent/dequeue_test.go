@@ -0,0 +1,399 @@
+package concurrent
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// The deques checked by the harness, a new DEQueue implementation only needs to be added here.
+// Every deque is used with a single owner (PushBottom and PopBottom) and any number of thieves
+// (PopTop) since that is how the executors use them.
+var dequeImplementations = []struct {
+	name       string
+	newDEQueue func() DEQueue
+}{
+	{"UnBounded", NewUnBoundedDEQueue},
+	{"ChaseLev", NewChaseLevDEQueue},
+	{"PriorityUnBounded", func() DEQueue { return newPriorityDEQueue(3, NewUnBoundedDEQueue) }},
+	{"PriorityChaseLev", func() DEQueue { return newPriorityDEQueue(3, NewChaseLevDEQueue) }},
+}
+
+// Runs the test on every deque implementation
+func forEachDEQueue(t *testing.T, test func(t *testing.T, newDEQueue func() DEQueue)) {
+	for _, implementation := range dequeImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			test(t, implementation.newDEQueue)
+		})
+	}
+}
+
+// Sequential model of a deque, tasks are ints
+// (top) model[0] -> ... -> model[len(model)-1] (bottom)
+type dequeModel []int
+
+func (m dequeModel) pushBottom(value int) dequeModel {
+	return append(m[:len(m):len(m)], value)
+}
+
+// Returns the model without its top task and the task (empty if the model is empty)
+func (m dequeModel) popTop() (dequeModel, int) {
+	if len(m) == 0 {
+		return m, empty
+	}
+	return m[1:], m[0]
+}
+
+// Returns the model without its bottom task and the task (empty if the model is empty)
+func (m dequeModel) popBottom() (dequeModel, int) {
+	if len(m) == 0 {
+		return m, empty
+	}
+	return m[:len(m)-1], m[len(m)-1]
+}
+
+// Value recorded for a pop that found the deque empty
+const empty = -1
+
+// Returns the value of a popped task (empty for nil), it may be called by any goroutine
+func valueOf(t *testing.T, task Task) int {
+	if task == nil {
+		return empty
+	}
+	value, ok := task.(int)
+	if !ok {
+		t.Errorf("popped %v (%T), which was never pushed", task, task)
+	}
+	return value
+}
+
+func TestDEQueueSequential(t *testing.T) {
+	forEachDEQueue(t, func(t *testing.T, newDEQueue func() DEQueue) {
+		queue := newDEQueue()
+		model := dequeModel{}
+		random := rand.New(rand.NewSource(1))
+
+		for i := 0; i < 20000; i++ {
+			// Favour pushes for a while and pops afterwards so that the deque grows and shrinks
+			pushBias := 6
+			if (i/1000)%2 == 1 {
+				pushBias = 3
+			}
+
+			var expected, actual int
+			switch op := random.Intn(10); {
+			case op < pushBias:
+				queue.PushBottom(i)
+				model = model.pushBottom(i)
+				continue
+			case op < pushBias+(10-pushBias)/2:
+				model, expected = model.popTop()
+				actual = valueOf(t, queue.PopTop())
+			default:
+				model, expected = model.popBottom()
+				actual = valueOf(t, queue.PopBottom())
+			}
+			if actual != expected {
+				t.Fatalf("operation %d: popped %d, expected %d", i, actual, expected)
+			}
+			if queue.Size() != len(model) || queue.IsEmpty() != (len(model) == 0) {
+				t.Fatalf("operation %d: size %d (empty %v), expected %d", i, queue.Size(), queue.IsEmpty(), len(model))
+			}
+		}
+	})
+}
+
+// Kinds of operations in a history
+const (
+	opPushBottom = iota
+	opPopBottom
+	opPopTop
+)
+
+// Operation of a history, call and ret are the logical times at which the operation was invoked
+// and returned
+type dequeOp struct {
+	kind  int
+	value int // The value pushed or popped (empty if the pop found the deque empty)
+	call  int64
+	ret   int64
+}
+
+func (op dequeOp) String() string {
+	names := []string{"PushBottom", "PopBottom", "PopTop"}
+	return fmt.Sprintf("%s(%d)@[%d,%d]", names[op.kind], op.value, op.call, op.ret)
+}
+
+// Records the operations of a goroutine on a deque with a logical clock shared by all goroutines
+type dequeRecorder struct {
+	t     *testing.T
+	queue DEQueue
+	clock *int64
+	ops   []dequeOp
+}
+
+func (r *dequeRecorder) pushBottom(value int) {
+	call := atomic.AddInt64(r.clock, 1)
+	r.queue.PushBottom(value)
+	r.ops = append(r.ops, dequeOp{kind: opPushBottom, value: value, call: call, ret: atomic.AddInt64(r.clock, 1)})
+}
+
+func (r *dequeRecorder) popBottom() {
+	call := atomic.AddInt64(r.clock, 1)
+	task := r.queue.PopBottom()
+	r.ops = append(r.ops, dequeOp{kind: opPopBottom, value: valueOf(r.t, task), call: call, ret: atomic.AddInt64(r.clock, 1)})
+}
+
+func (r *dequeRecorder) popTop() {
+	call := atomic.AddInt64(r.clock, 1)
+	task := r.queue.PopTop()
+	r.ops = append(r.ops, dequeOp{kind: opPopTop, value: valueOf(r.t, task), call: call, ret: atomic.AddInt64(r.clock, 1)})
+}
+
+// Returns whether the history can be ordered so that every operation takes effect at a single point
+// between its call and its return and the results match the model starting from the initial
+// state (Wing and Gong, "Testing and Verifying Concurrent Objects", 1993). Histories are limited to
+// 64 operations.
+func linearizable(initial dequeModel, history []dequeOp) bool {
+	// States already known not to lead to a linearization
+	dead := map[string]bool{}
+
+	var search func(done uint64, model dequeModel) bool
+	search = func(done uint64, model dequeModel) bool {
+		if done == 1<<uint(len(history))-1 {
+			return true
+		}
+		key := fmt.Sprint(done, model)
+		if dead[key] {
+			return false
+		}
+
+		for i, op := range history {
+			if done&(1<<uint(i)) != 0 || !minimal(history, done, op) {
+				continue
+			}
+
+			// Apply the operation to the model and check its result
+			next, value := model, op.value
+			switch op.kind {
+			case opPushBottom:
+				next = model.pushBottom(op.value)
+			case opPopBottom:
+				next, value = model.popBottom()
+			case opPopTop:
+				next, value = model.popTop()
+			}
+			if value == op.value && search(done|1<<uint(i), next) {
+				return true
+			}
+		}
+
+		dead[key] = true
+		return false
+	}
+	return search(0, initial)
+}
+
+// Returns whether the operation may take effect next, i.e. no pending operation returned before
+// it was called
+func minimal(history []dequeOp, done uint64, op dequeOp) bool {
+	for i, other := range history {
+		if done&(1<<uint(i)) == 0 && other.ret < op.call {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDEQueueLinearizable(t *testing.T) {
+	rounds, thieves := 2000, 2
+	if testing.Short() {
+		rounds = 200
+	}
+
+	forEachDEQueue(t, func(t *testing.T, newDEQueue func() DEQueue) {
+		random := rand.New(rand.NewSource(1))
+
+		for round := 0; round < rounds; round++ {
+			// Start from a few tasks so that the owner and the thieves race for the last ones
+			queue := newDEQueue()
+			initial := dequeModel{}
+			for i, n := 0, random.Intn(3); i < n; i++ {
+				queue.PushBottom(i)
+				initial = initial.pushBottom(i)
+			}
+
+			clock := int64(0)
+			owner := &dequeRecorder{t: t, queue: queue, clock: &clock}
+			recorders := []*dequeRecorder{owner}
+			ownerOps := []int{}
+			for i := 0; i < 6; i++ {
+				ownerOps = append(ownerOps, random.Intn(2))
+			}
+
+			start := make(chan struct{})
+			wg := &sync.WaitGroup{}
+			wg.Add(thieves + 1)
+			go func() {
+				defer wg.Done()
+				<-start
+				for i, op := range ownerOps {
+					if op == 0 {
+						owner.pushBottom(100 + i)
+					} else {
+						owner.popBottom()
+					}
+				}
+			}()
+			for i := 0; i < thieves; i++ {
+				thief := &dequeRecorder{t: t, queue: queue, clock: &clock}
+				recorders = append(recorders, thief)
+				go func() {
+					defer wg.Done()
+					<-start
+					for j := 0; j < 3; j++ {
+						thief.popTop()
+						runtime.Gosched()
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			history := []dequeOp{}
+			for _, recorder := range recorders {
+				history = append(history, recorder.ops...)
+			}
+			if !linearizable(initial, history) {
+				t.Fatalf("round %d: history is not linearizable from %v: %v", round, initial, history)
+			}
+		}
+	})
+}
+
+// A thief that observed the top index and was preempted before reading the task must lose the race
+// (and not panic) once other thieves moved the top past it and the owner grew the array, which
+// only copies the tasks that are left. The stress test rarely produces this interleaving.
+func TestChaseLevGrowWhileThiefStalled(t *testing.T) {
+	queue := NewChaseLevDEQueue().(*ChaseLevDEQueue)
+	for i := 0; i < 4; i++ {
+		queue.PushBottom(i)
+	}
+
+	// The stalled thief observes the top task
+	top := atomic.LoadInt64(&queue.top)
+	bottom := atomic.LoadInt64(&queue.bottom)
+
+	// Other thieves steal every task and the owner pushes until the array grows
+	for i := 0; i < 4; i++ {
+		if value := valueOf(t, queue.PopTop()); value != i {
+			t.Fatalf("stole %d, expected %d", value, i)
+		}
+	}
+	array := queue.loadArray()
+	for i := 4; i < 4+chaseLevInitialSize; i++ {
+		queue.PushBottom(i)
+	}
+	if queue.loadArray() == array {
+		t.Fatal("the array did not grow")
+	}
+
+	// The stalled thief resumes
+	if task, ok := queue.steal(top, bottom); ok {
+		t.Fatalf("stalled thief stole %v", task)
+	}
+	if value := valueOf(t, queue.PopTop()); value != 4 {
+		t.Fatalf("stole %d, expected 4", value)
+	}
+}
+
+func TestDEQueueStress(t *testing.T) {
+	tasks, thieves := 200000, 4
+	if testing.Short() {
+		tasks = 20000
+	}
+
+	forEachDEQueue(t, func(t *testing.T, newDEQueue func() DEQueue) {
+		queue := newDEQueue()
+		done := int32(0)
+		popped := make([][]int, thieves+1)
+
+		wg := &sync.WaitGroup{}
+		wg.Add(thieves)
+		for i := 1; i <= thieves; i++ {
+			i := i
+			go func() {
+				defer wg.Done()
+				// Keep stealing until the owner is done and the deque is drained
+				for {
+					task := queue.PopTop()
+					if task == nil {
+						if atomic.LoadInt32(&done) == 1 && queue.IsEmpty() {
+							return
+						}
+						runtime.Gosched()
+						continue
+					}
+					popped[i] = append(popped[i], valueOf(t, task))
+				}
+			}()
+		}
+
+		// The owner pushes bursts of tasks (growing bounded arrays) and pops some of them back
+		random := rand.New(rand.NewSource(1))
+		for next := 0; next < tasks; {
+			for burst := random.Intn(100); burst >= 0 && next < tasks; burst-- {
+				queue.PushBottom(next)
+				next++
+			}
+			for pops := random.Intn(60); pops > 0; pops-- {
+				if task := queue.PopBottom(); task != nil {
+					popped[0] = append(popped[0], valueOf(t, task))
+				}
+			}
+			// Let the thieves catch up so that they race each other and the owner
+			runtime.Gosched()
+		}
+		for task := queue.PopBottom(); task != nil; task = queue.PopBottom() {
+			popped[0] = append(popped[0], valueOf(t, task))
+		}
+		atomic.StoreInt32(&done, 1)
+		wg.Wait()
+
+		// Every task was popped exactly once
+		seen := make([]bool, tasks)
+		for _, values := range popped {
+			for _, value := range values {
+				if value < 0 || value >= tasks {
+					t.Fatalf("popped %d, which was never pushed", value)
+				}
+				if seen[value] {
+					t.Fatalf("popped %d twice", value)
+				}
+				seen[value] = true
+			}
+		}
+		for value, ok := range seen {
+			if !ok {
+				t.Fatalf("lost %d", value)
+			}
+		}
+
+		// Tasks are pushed in increasing order so the top of the deque only ever increases and
+		// every thief must steal increasing tasks
+		for i := 1; i <= thieves; i++ {
+			for j := 1; j < len(popped[i]); j++ {
+				if popped[i][j] <= popped[i][j-1] {
+					t.Fatalf("thief %d stole %d after %d", i, popped[i][j], popped[i][j-1])
+				}
+			}
+		}
+		if !queue.IsEmpty() || queue.Size() != 0 {
+			t.Fatalf("deque is not empty after being drained (size %d)", queue.Size())
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package concurrent
+
+import (
+	"testing"
+)
+
+// Callable returning the square of its number
+type squareTask struct {
+	n int
+}
+
+func (task *squareTask) Call() interface{} {
+	return task.n * task.n
+}
+
+// The executors tested and benchmarked, each is created with the given number of workers
+var executorImplementations = []struct {
+	name        string
+	newExecutor func(capacity int) ExecutorService
+}{
+	{"ws", func(capacity int) ExecutorService { return NewWorkStealingExecutor(capacity, 1) }},
+	{"ws-chaselev", func(capacity int) ExecutorService {
+		return NewWorkStealingExecutor(capacity, 1, WithDEQueue(NewChaseLevDEQueue))
+	}},
+	{"wb", func(capacity int) ExecutorService { return NewWorkBalancingExecutor(capacity, 1, 2) }},
+	{"wsh", func(capacity int) ExecutorService { return NewWorkSharingExecutor(capacity) }},
+}
+
+// Runs the test on every executor created with the given number of workers
+func forEachExecutorService(t *testing.T, capacity int, test func(t *testing.T, executor ExecutorService)) {
+	for _, implementation := range executorImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			executor := implementation.newExecutor(capacity)
+			defer executor.Shutdown()
+			test(t, executor)
+		})
+	}
+}
+
+// Submits the squares of 0 to n-1 and checks the values of their Futures
+func checkSquares(t *testing.T, executor ExecutorService, n int) {
+	futures := []Future{}
+	for i := 0; i < n; i++ {
+		futures = append(futures, executor.Submit(&squareTask{n: i}))
+	}
+	for i, future := range futures {
+		if value, ok := future.Get().(int); !ok || value != i*i {
+			t.Fatalf("task %d returned %v, expected %d", i, future.Get(), i*i)
+		}
+	}
+}
+
+func TestExecutorCallable(t *testing.T) {
+	for _, capacity := range []int{1, 4} {
+		forEachExecutorService(t, capacity, func(t *testing.T, executor ExecutorService) {
+			checkSquares(t, executor, 2000)
+		})
+	}
+}
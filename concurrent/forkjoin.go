@@ -0,0 +1,116 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Time a joining worker waits for the joined task before looking for work to help with again
+const joinWaitInterval = time.Millisecond
+
+// forkJoinWorker gives the fork-join tasks run by a worker access to the queues of the executor
+type forkJoinWorker struct {
+	id        int
+	queue     *localQueue   // Local queue of the worker (nil if the workers share the injection queue)
+	queues    []*localQueue // Local queues of the workers (nil if the workers share the injection queue)
+	injection DEQueue
+	signal    *idleSignal
+	victims   VictimSelector
+	stats     *workerStats
+}
+
+// Returns the fork-join part of the worker with the given id
+func newForkJoinWorker(id int, queue *localQueue, queues []*localQueue, injection DEQueue, signal *idleSignal, victims VictimSelector, stats *workerStats) *forkJoinWorker {
+	return &forkJoinWorker{
+		id:        id,
+		queue:     queue,
+		queues:    queues,
+		injection: injection,
+		signal:    signal,
+		victims:   victims,
+		stats:     stats,
+	}
+}
+
+// Push a forked task onto the local queue of the worker
+func (worker *forkJoinWorker) push(job *future) {
+	// Without local queues the newest task of the shared queue is the forked task
+	if worker.queue == nil {
+		worker.injection.PushBottom(job)
+		worker.signal.notify()
+		return
+	}
+	worker.queue.pushLocal(job)
+}
+
+// Find a task to run while joining: the newest local task first (most likely the joined task
+// itself), then a submitted task and finally a task stolen from another worker
+func (worker *forkJoinWorker) help() Task {
+	if worker.queue == nil {
+		return worker.injection.PopBottom()
+	}
+	if task := worker.queue.PopBottom(); task != nil {
+		return task
+	}
+	if task := worker.injection.PopTop(); task != nil {
+		return task
+	}
+	if len(worker.queues) < 2 {
+		return nil
+	}
+
+	victim := worker.victims.Next(func(victim int) int { return worker.queues[victim].Size() })
+	task := worker.queues[victim].PopTop()
+	worker.victims.Report(victim, task != nil)
+	return task
+}
+
+// forkJoin is the ForkJoin of a single running task
+type forkJoin struct {
+	worker *forkJoinWorker
+	parent *future // Future of the running task
+}
+
+// Fork pushes a subtask onto the local queue of the worker
+func (fj *forkJoin) Fork(task interface{}) Future {
+	// Subtasks inherit the context and the priority of the forking task
+	job := newFuture(fj.parent.ctx, task)
+	job.priority = fj.parent.priority
+	fj.worker.push(job)
+	return job
+}
+
+// Join waits for a subtask while running the other pending tasks
+func (fj *forkJoin) Join(f Future) interface{} {
+	selectable, ok := f.(SelectableFuture)
+	if !ok {
+		return f.Get()
+	}
+
+	for {
+		select {
+		case <-selectable.Done():
+			return f.Get()
+		default:
+		}
+
+		// Help with the pending work instead of blocking the worker
+		if task := fj.worker.help(); task != nil {
+			fj.worker.stats.helpTask(task, fj.worker)
+			continue
+		}
+
+		// The joined task is running on another worker, check again for work once in a while
+		// since it may fork tasks that need help as well
+		select {
+		case <-selectable.Done():
+			return f.Get()
+		case <-time.After(joinWaitInterval):
+		}
+	}
+}
+
+// Context returns the context of the running task
+func (fj *forkJoin) Context() context.Context {
+	return fj.parent.ctx
+}
@@ -0,0 +1,58 @@
+package concurrent
+
+import (
+	"testing"
+)
+
+// ForkJoinTask computing the n-th Fibonacci number by forking the first recursive call
+type fibonacciTask int
+
+func (n fibonacciTask) Compute(fj ForkJoin) interface{} {
+	if n < 2 {
+		return int(n)
+	}
+	first := fj.Fork(n - 1)
+	second := (n - 2).Compute(fj).(int)
+	return fj.Join(first).(int) + second
+}
+
+func TestForkJoin(t *testing.T) {
+	// A single worker has to run the forked subtasks while it joins them
+	for _, capacity := range []int{1, 4} {
+		forEachExecutorService(t, capacity, func(t *testing.T, executor ExecutorService) {
+			futures := []Future{}
+			for i := 0; i < 4; i++ {
+				futures = append(futures, executor.Submit(fibonacciTask(18)))
+			}
+			for _, future := range futures {
+				if value := future.Get(); value != 2584 {
+					t.Fatalf("fib(18) returned %v, expected 2584", value)
+				}
+			}
+		})
+	}
+}
+
+// ForkJoinTask forking its child and panicking with the error of the child if it failed
+type forkingTask struct {
+	child interface{}
+}
+
+func (task forkingTask) Compute(fj ForkJoin) interface{} {
+	child := fj.Fork(task.child)
+	value := fj.Join(child)
+	if err := child.(ErrFuture).Err(); err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// A subtask that panics fails its join and the panic surfaces in the Future of the root task
+func TestForkJoinPanic(t *testing.T) {
+	forEachExecutorService(t, 2, func(t *testing.T, executor ExecutorService) {
+		root := executor.Submit(forkingTask{child: &panickingTask{message: "boom"}})
+		if _, ok := root.(ErrFuture).Err().(*PanicError); !ok {
+			t.Fatalf("root task failed with %v", root.(ErrFuture).Err())
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidTask is the error of a Future whose task is neither a Runnable, a Callable nor a ForkJoinTask
+var ErrInvalidTask = errors.New("concurrent: task is neither a Runnable, a Callable nor a ForkJoinTask")
+
+// PanicError is the error of a Future whose task panicked
+type PanicError struct {
+	Value interface{} // The value the task panicked with
+	Stack []byte      // The stack trace of the goroutine at the time of the panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("concurrent: task panicked: %v", e.Value)
+}
+
+// States of a future
+const (
+	futurePending int32 = iota // Waiting in a queue
+	futureRunning              // Picked up by a worker
+	futureDone                 // Completed, failed or cancelled
+)
+
+// future wraps every task submitted to an executor. Workers run the wrapped task through the
+// future, which stores the result and wakes up every goroutine waiting on it so that the
+// submitted tasks themselves do not need to carry any synchronization code.
+type future struct {
+	task      interface{}     // The Runnable or Callable that was submitted
+	ctx       context.Context // The context the task was submitted with
+	priority  Priority        // The priority the task was submitted with
+	state     int32
+	value     interface{}   // The value returned by a Callable (nil for a Runnable)
+	err       error         // Why the task did not complete (e.g. the context was cancelled)
+	done      chan struct{} // Closed once the future is resolved
+	mu        sync.Mutex    // Guards the callbacks
+	callbacks []func()      // Called once the future is resolved (e.g. to release dependent tasks)
+}
+
+// Returns a new future for the given task
+func newFuture(ctx context.Context, task interface{}) *future {
+	f := &future{
+		task:     task,
+		ctx:      ctx,
+		priority: DefaultPriority,
+		state:    futurePending,
+		value:    nil,
+		err:      nil,
+		done:     make(chan struct{}),
+	}
+
+	// Resolve the future as soon as the context is done if the task has not started by then
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				f.cancel(ctx.Err())
+			case <-f.done:
+			}
+		}()
+	}
+	return f
+}
+
+// Run the wrapped task and complete the future with its result, returns the name of the task
+// in the trace of the worker (empty if the task was skipped or the worker is not traced)
+func (f *future) run(worker *forkJoinWorker) string {
+	// Skip the task if it was cancelled while waiting in a queue
+	if !atomic.CompareAndSwapInt32(&f.state, futurePending, futureRunning) {
+		return ""
+	}
+	if err := f.ctx.Err(); err != nil {
+		f.complete(nil, err)
+		return ""
+	}
+
+	// The task is released once the future completes
+	name := worker.stats.traceName(f.task)
+	value, err := f.execute(worker)
+	f.complete(value, err)
+	return name
+}
+
+// Execute the wrapped task, a panic is recovered and returned as a PanicError so that
+// the worker running the task survives it
+func (f *future) execute(worker *forkJoinWorker) (value interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			value = nil
+			err = &PanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+
+	// Tasks that accept a context can observe the cancellation while running, they return the
+	// error of the context if they stopped early
+	switch task := f.task.(type) {
+	case ForkJoinTask:
+		value, err = f.stoppedEarly(task.Compute(&forkJoin{worker: worker, parent: f}))
+	case ContextCallable:
+		value, err = f.stoppedEarly(task.CallContext(f.ctx))
+	case ContextRunnable:
+		err = task.RunContext(f.ctx)
+	case Callable:
+		value = task.Call()
+	case Runnable:
+		task.Run()
+	default:
+		err = ErrInvalidTask
+	}
+	return value, err
+}
+
+// Returns the value of a task that ran to completion, or the error of the context if the task
+// returned it because it stopped early
+func (f *future) stoppedEarly(value interface{}) (interface{}, error) {
+	if err, ok := value.(error); ok && f.ctx.Err() != nil && errors.Is(err, f.ctx.Err()) {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Resolve the future with the error if the task has not started yet
+func (f *future) cancel(err error) {
+	f.withdraw(err)
+}
+
+// Resolve the future with the error if the task has not started yet, returns the task and
+// whether it was withdrawn
+func (f *future) withdraw(err error) (interface{}, bool) {
+	if !atomic.CompareAndSwapInt32(&f.state, futurePending, futureDone) {
+		return nil, false
+	}
+	task := f.task
+	f.complete(nil, err)
+	return task, true
+}
+
+// Store the result and wake up every goroutine waiting on it
+func (f *future) complete(value interface{}, err error) {
+	// Release the task so that it can be garbage collected while the future is still referenced
+	f.task = nil
+	f.value = value
+	f.err = err
+	atomic.StoreInt32(&f.state, futureDone)
+	close(f.done)
+
+	// Run the callbacks registered before the future was resolved
+	f.mu.Lock()
+	callbacks := f.callbacks
+	f.callbacks = nil
+	f.mu.Unlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// Call back once the future is resolved (right away if it already is)
+func (f *future) onDone(callback func()) {
+	f.mu.Lock()
+	select {
+	case <-f.done:
+		f.mu.Unlock()
+		callback()
+	default:
+		f.callbacks = append(f.callbacks, callback)
+		f.mu.Unlock()
+	}
+}
+
+// Get waits for the task to complete and returns the value of a Callable or nil for a Runnable
+// (nil as well if the task was cancelled)
+func (f *future) Get() interface{} {
+	<-f.done
+	return f.value
+}
+
+// Err waits for the task to complete and returns why it did not complete (nil if it did)
+func (f *future) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Done returns a channel that is closed once the task has completed
+func (f *future) Done() <-chan struct{} {
+	return f.done
+}
+
+// GetTimeout waits at most timeout for the task to complete
+func (f *future) GetTimeout(timeout time.Duration) (interface{}, error) {
+	// A resolved future never times out, even with a timeout that already expired
+	select {
+	case <-f.done:
+		return f.value, f.err
+	default:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-timer.C:
+		return nil, ErrTimeout
+	}
+}
+
+// Run a task popped from a queue on the given worker, returns the name of the task in the trace
+func runTask(task Task, worker *forkJoinWorker) string {
+	if f, ok := task.(*future); ok {
+		return f.run(worker)
+	}
+	return ""
+}
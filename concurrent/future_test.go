@@ -0,0 +1,219 @@
+package concurrent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Runnable blocking its worker until the channel is closed
+type blockingTask struct {
+	release chan struct{}
+}
+
+func (task *blockingTask) Run() {
+	<-task.release
+}
+
+// ContextCallable returning the error of its context once it is done
+type contextTask struct{}
+
+func (task *contextTask) Call() interface{} {
+	return task.CallContext(context.Background())
+}
+
+func (task *contextTask) CallContext(ctx context.Context) interface{} {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSubmitContext(t *testing.T) {
+	forEachExecutorService(t, 1, func(t *testing.T, executor ExecutorService) {
+		contextExecutor := executor.(ContextExecutorService)
+
+		// Keep the only worker busy so that the following tasks stay queued
+		blocker := &blockingTask{release: make(chan struct{})}
+		blocked := executor.Submit(blocker)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelled := []Future{}
+		for i := 0; i < 5; i++ {
+			cancelled = append(cancelled, contextExecutor.SubmitContext(ctx, &squareTask{n: i}))
+		}
+		timeoutCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancelTimeout()
+		timedOut := contextExecutor.SubmitContext(timeoutCtx, &squareTask{n: 2})
+
+		// Queued tasks are resolved as soon as their context is done, before the worker frees up
+		cancel()
+		for _, future := range cancelled {
+			if err := future.(ErrFuture).Err(); err != context.Canceled {
+				t.Fatalf("cancelled task failed with %v", err)
+			}
+			if value := future.Get(); value != nil {
+				t.Fatalf("cancelled task returned %v", value)
+			}
+		}
+		if err := timedOut.(ErrFuture).Err(); err != context.DeadlineExceeded {
+			t.Fatalf("timed out task failed with %v", err)
+		}
+
+		// A running task observes the cancellation through its context
+		close(blocker.release)
+		if err := blocked.(ErrFuture).Err(); err != nil {
+			t.Fatalf("blocking task failed with %v", err)
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+		running := contextExecutor.SubmitContext(ctx, &contextTask{})
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+		if err := running.(ErrFuture).Err(); err != context.Canceled {
+			t.Fatalf("running task failed with %v", err)
+		}
+		if value := contextExecutor.SubmitContext(context.Background(), &squareTask{n: 3}).Get(); value != 9 {
+			t.Fatalf("task returned %v, expected 9", value)
+		}
+	})
+}
+
+// ContextCallable cancelling its context right before it completes
+type lateCancelCallable struct {
+	cancel context.CancelFunc
+}
+
+func (task *lateCancelCallable) Call() interface{} {
+	return task.CallContext(context.Background())
+}
+
+func (task *lateCancelCallable) CallContext(ctx context.Context) interface{} {
+	task.cancel()
+	return 42
+}
+
+// ContextRunnable cancelling its context right before it completes, or stops early
+type lateCancelRunnable struct {
+	cancel    context.CancelFunc
+	stopEarly bool
+}
+
+func (task *lateCancelRunnable) Run() {
+	task.RunContext(context.Background())
+}
+
+func (task *lateCancelRunnable) RunContext(ctx context.Context) error {
+	task.cancel()
+	if task.stopEarly {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// ForkJoinTask cancelling its context right before it completes
+type lateCancelForkJoin struct {
+	cancel context.CancelFunc
+}
+
+func (task *lateCancelForkJoin) Compute(fj ForkJoin) interface{} {
+	task.cancel()
+	return 42
+}
+
+// A task that ran to completion succeeds even if its context is done by the time it returns,
+// the error of the context is only reported if the task returned it
+func TestSubmitContextCompleted(t *testing.T) {
+	forEachExecutorService(t, 1, func(t *testing.T, executor ExecutorService) {
+		contextExecutor := executor.(ContextExecutorService)
+		submit := func(newTask func(cancel context.CancelFunc) interface{}) (interface{}, error) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			return contextExecutor.SubmitContext(ctx, newTask(cancel)).(SelectableFuture).GetTimeout(time.Second)
+		}
+
+		if value, err := submit(func(cancel context.CancelFunc) interface{} {
+			return &lateCancelCallable{cancel: cancel}
+		}); value != 42 || err != nil {
+			t.Fatalf("completed ContextCallable returned %v, %v, expected 42", value, err)
+		}
+		if value, err := submit(func(cancel context.CancelFunc) interface{} {
+			return &lateCancelForkJoin{cancel: cancel}
+		}); value != 42 || err != nil {
+			t.Fatalf("completed ForkJoinTask returned %v, %v, expected 42", value, err)
+		}
+		if _, err := submit(func(cancel context.CancelFunc) interface{} {
+			return &lateCancelRunnable{cancel: cancel}
+		}); err != nil {
+			t.Fatalf("completed ContextRunnable failed with %v", err)
+		}
+		if _, err := submit(func(cancel context.CancelFunc) interface{} {
+			return &lateCancelRunnable{cancel: cancel, stopEarly: true}
+		}); err != context.Canceled {
+			t.Fatalf("ContextRunnable stopped early failed with %v", err)
+		}
+	})
+}
+
+func TestGetTimeout(t *testing.T) {
+	forEachExecutorService(t, 1, func(t *testing.T, executor ExecutorService) {
+		blocker := &blockingTask{release: make(chan struct{})}
+		future := executor.Submit(blocker).(SelectableFuture)
+
+		if value, err := future.GetTimeout(5 * time.Millisecond); value != nil || err != ErrTimeout {
+			t.Fatalf("pending task returned %v, %v", value, err)
+		}
+		select {
+		case <-future.Done():
+			t.Fatal("pending task is done")
+		default:
+		}
+
+		close(blocker.release)
+		select {
+		case <-future.Done():
+		case <-time.After(time.Second):
+			t.Fatal("task is not done a second after it was released")
+		}
+		if value, err := future.GetTimeout(0); value != nil || err != nil {
+			t.Fatalf("completed task returned %v, %v", value, err)
+		}
+
+		squared := executor.Submit(&squareTask{n: 5}).(SelectableFuture)
+		if value, err := squared.GetTimeout(time.Second); value != 25 || err != nil {
+			t.Fatalf("task returned %v, %v, expected 25", value, err)
+		}
+	})
+}
+
+// Callable panicking with its message
+type panickingTask struct {
+	message string
+}
+
+func (task *panickingTask) Call() interface{} {
+	panic(task.message)
+}
+
+func TestTaskPanic(t *testing.T) {
+	forEachExecutorService(t, 1, func(t *testing.T, executor ExecutorService) {
+		failed := executor.Submit(&panickingTask{message: "boom"}).(ErrFuture)
+		squared := executor.Submit(&squareTask{n: 4})
+
+		panicErr, ok := failed.Err().(*PanicError)
+		if !ok {
+			t.Fatalf("panicking task failed with %v", failed.Err())
+		}
+		if panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+			t.Fatalf("panic error %v has no stack or the wrong value", panicErr)
+		}
+		if value := failed.Get(); value != nil {
+			t.Fatalf("panicking task returned %v", value)
+		}
+
+		// The worker survives the panic
+		if value := squared.Get(); value != 16 {
+			t.Fatalf("task returned %v, expected 16", value)
+		}
+		if err := executor.Submit(42).(ErrFuture).Err(); err != ErrInvalidTask {
+			t.Fatalf("invalid task failed with %v", err)
+		}
+	})
+}
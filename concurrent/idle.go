@@ -0,0 +1,117 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// IdleStrategy decides what a worker does after it failed to find any work
+type IdleStrategy int
+
+const (
+	// IdlePark spins, then yields the processor and finally parks the worker until new work
+	// is pushed or the executor is shut down
+	IdlePark IdleStrategy = iota
+	// IdleYield yields the processor between two attempts at finding work
+	IdleYield
+	// IdleSpin busy-waits between two attempts at finding work
+	IdleSpin
+)
+
+// Number of failed attempts at finding work before an idle worker starts yielding / parking
+const (
+	idleSpinAttempts  = 64
+	idleYieldAttempts = 64
+)
+
+// idleSignal wakes up the parked workers of an executor whenever new work is pushed
+type idleSignal struct {
+	epoch  uint64 // Incremented every time new work is pushed or the executor is shut down
+	parked int32  // Number of parked workers
+	cond   *sync.Cond
+}
+
+func newIdleSignal() *idleSignal {
+	return &idleSignal{
+		epoch:  0,
+		parked: 0,
+		cond:   sync.NewCond(&sync.Mutex{}),
+	}
+}
+
+// Returns the current epoch, it must be read before looking for work
+func (s *idleSignal) observe() uint64 {
+	return atomic.LoadUint64(&s.epoch)
+}
+
+// Wake up all parked workers
+func (s *idleSignal) notify() {
+	atomic.AddUint64(&s.epoch, 1)
+
+	// Only take the lock if a worker may be parked
+	if atomic.LoadInt32(&s.parked) > 0 {
+		s.cond.L.Lock()
+		s.cond.Broadcast()
+		s.cond.L.Unlock()
+	}
+}
+
+// Park the calling worker until the epoch moves past the observed epoch
+func (s *idleSignal) park(epoch uint64) {
+	s.cond.L.Lock()
+	atomic.AddInt32(&s.parked, 1)
+	for atomic.LoadUint64(&s.epoch) == epoch {
+		s.cond.Wait()
+	}
+	atomic.AddInt32(&s.parked, -1)
+	s.cond.L.Unlock()
+}
+
+// idler applies the idle strategy of a single worker
+type idler struct {
+	strategy IdleStrategy
+	signal   *idleSignal
+	attempts int // Consecutive failed attempts at finding work
+}
+
+func newIdler(strategy IdleStrategy, signal *idleSignal) *idler {
+	return &idler{
+		strategy: strategy,
+		signal:   signal,
+		attempts: 0,
+	}
+}
+
+// Returns the current epoch of the signal, it must be read before looking for work
+func (i *idler) observe() uint64 {
+	return i.signal.observe()
+}
+
+// The worker found work
+func (i *idler) reset() {
+	i.attempts = 0
+}
+
+// The worker failed to find work since it observed the epoch. Parking is only allowed once
+// the whole work pool is empty since all new work is then announced through the signal.
+func (i *idler) idle(epoch uint64, isWorkPoolEmpty func() bool) {
+	i.attempts++
+
+	switch i.strategy {
+	case IdleSpin:
+		return
+	case IdleYield:
+		runtime.Gosched()
+	case IdlePark:
+		if i.attempts <= idleSpinAttempts {
+			return
+		}
+		if i.attempts <= idleSpinAttempts+idleYieldAttempts || !isWorkPoolEmpty() {
+			runtime.Gosched()
+			return
+		}
+		i.signal.park(epoch)
+		i.attempts = 0
+	}
+}
@@ -0,0 +1,71 @@
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Parked workers are woken up by the next notification and only by a notification
+func TestIdleSignal(t *testing.T) {
+	signal := newIdleSignal()
+	woken := int32(0)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(3)
+	for i := 0; i < 3; i++ {
+		epoch := signal.observe()
+		go func() {
+			defer wg.Done()
+			signal.park(epoch)
+			atomic.AddInt32(&woken, 1)
+		}()
+	}
+	for atomic.LoadInt32(&signal.parked) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&woken) != 0 {
+		t.Fatal("a worker woke up without a notification")
+	}
+
+	signal.notify()
+	wg.Wait()
+	if atomic.LoadInt32(&signal.parked) != 0 {
+		t.Fatalf("%d workers still parked", signal.parked)
+	}
+
+	// A worker that observed an older epoch does not park at all
+	epoch := signal.observe()
+	signal.notify()
+	signal.park(epoch)
+}
+
+// Work submitted after the workers went idle still runs whatever the idle strategy
+func TestIdleStrategies(t *testing.T) {
+	strategies := []struct {
+		name     string
+		strategy IdleStrategy
+	}{
+		{"park", IdlePark},
+		{"yield", IdleYield},
+		{"spin", IdleSpin},
+	}
+	for _, strategy := range strategies {
+		strategy := strategy
+		t.Run(strategy.name, func(t *testing.T) {
+			executors := []ExecutorService{
+				NewWorkStealingExecutor(4, 1, WithIdleStrategy(strategy.strategy)),
+				NewWorkBalancingExecutor(4, 1, 2, WithIdleStrategy(strategy.strategy)),
+				NewWorkSharingExecutor(4, WithIdleStrategy(strategy.strategy)),
+			}
+			for _, executor := range executors {
+				for round := 0; round < 3; round++ {
+					time.Sleep(20 * time.Millisecond)
+					checkSquares(t, executor, 100)
+				}
+				executor.Shutdown()
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package concurrent
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShutdown is the error of the Future of a task that was removed from an executor by ShutdownNow
+var ErrShutdown = errors.New("concurrent: executor was shut down before the task started")
+
+// ErrRejected is the error of the Future of a task submitted after the executor was shut down
+var ErrRejected = errors.New("concurrent: executor is shut down, task rejected")
+
+// States of an executor
+const (
+	stateRunning    int32 = iota // Accepting tasks
+	stateShutdown                // Rejecting tasks, the workers exit once the work pool is empty
+	stateTerminated              // Every worker has exited
+)
+
+// lifecycle is the state machine of an executor, shared by the service and its workers.
+// (running) -> shutdown -> terminated
+// Submit may be called concurrently with itself and with Shutdown: a task is either rejected
+// or pushed while the workers are guaranteed to still be looking for work.
+type lifecycle struct {
+	state      int32
+	pending    int64         // Number of accepted submissions and retiring workers that may still push tasks
+	signal     *idleSignal   // Wakes up the parked workers when the state changes
+	stopping   chan struct{} // Closed once the shutdown was initiated
+	terminated chan struct{} // Closed once every worker has exited
+	mu         sync.Mutex
+	waiting    map[*future]uint64 // Accepted jobs waiting for their dependencies and when they were submitted, guarded by mu
+	waitingSeq uint64             // Orders the waiting jobs by submission, guarded by mu
+}
+
+// Returns a new lifecycle in the running state
+func newLifecycle(signal *idleSignal) *lifecycle {
+	return &lifecycle{
+		state:      stateRunning,
+		pending:    0,
+		signal:     signal,
+		stopping:   make(chan struct{}),
+		terminated: make(chan struct{}),
+		waiting:    map[*future]uint64{},
+		waitingSeq: 0,
+	}
+}
+
+// Move to the terminated state once every worker of the wait group is done
+func (l *lifecycle) watch(wg *sync.WaitGroup) {
+	go func() {
+		wg.Wait()
+		atomic.StoreInt32(&l.state, stateTerminated)
+		close(l.terminated)
+	}()
+}
+
+// Start submitting a task, returns false if the executor no longer accepts tasks.
+// An accepted submission must be ended with release once its task was pushed.
+func (l *lifecycle) beginSubmit() bool {
+	// Announce the submission before checking the state so that a worker observing the
+	// shutdown also observes the submission (or the task it pushed)
+	l.hold()
+	if atomic.LoadInt32(&l.state) != stateRunning {
+		l.release()
+		return false
+	}
+	return true
+}
+
+// Keep the workers from exiting on shutdown until release is called since tasks may still be pushed
+func (l *lifecycle) hold() {
+	atomic.AddInt64(&l.pending, 1)
+}
+
+// End a submission started with beginSubmit or a hold
+func (l *lifecycle) release() {
+	atomic.AddInt64(&l.pending, -1)
+
+	// Workers waiting for the submission to end may be parked
+	if atomic.LoadInt32(&l.state) != stateRunning {
+		l.signal.notify()
+	}
+}
+
+// Keep track of an accepted job that is not pushed before its dependencies are done, so that
+// ShutdownNow can remove it
+func (l *lifecycle) addWaiting(job *future) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.waitingSeq++
+	l.waiting[job] = l.waitingSeq
+}
+
+// Stop tracking a job once it was pushed or cancelled
+func (l *lifecycle) removeWaiting(job *future) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.waiting, job)
+}
+
+// Remove every job waiting for its dependencies, their futures resolve with ErrShutdown.
+// Returns the tasks as they were submitted, in submission order.
+func (l *lifecycle) drainWaiting() []interface{} {
+	l.mu.Lock()
+	jobs := make([]*future, 0, len(l.waiting))
+	for job := range l.waiting {
+		jobs = append(jobs, job)
+	}
+	waiting := l.waiting
+	l.waiting = map[*future]uint64{}
+	l.mu.Unlock()
+
+	// Withdraw the dependents before their dependencies so that they are not skipped with a
+	// DependencyError instead
+	sort.Slice(jobs, func(i, j int) bool { return waiting[jobs[i]] > waiting[jobs[j]] })
+	tasks := []interface{}{}
+	for _, job := range jobs {
+		// Jobs that were cancelled or pushed meanwhile are not returned
+		if task, ok := job.withdraw(ErrShutdown); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+		tasks[i], tasks[j] = tasks[j], tasks[i]
+	}
+	return tasks
+}
+
+// Stop accepting tasks, returns whether this call initiated the shutdown
+func (l *lifecycle) shutdown() bool {
+	initiated := atomic.CompareAndSwapInt32(&l.state, stateRunning, stateShutdown)
+	if initiated {
+		close(l.stopping)
+	}
+
+	// Wake up the parked workers so that they can exit
+	l.signal.notify()
+	return initiated
+}
+
+// Returns whether new tasks may still be pushed, i.e. the executor is running or a submission
+// accepted before the shutdown has not pushed its task yet
+func (l *lifecycle) open() bool {
+	// The state must be read before the submissions (see beginSubmit)
+	return atomic.LoadInt32(&l.state) == stateRunning || atomic.LoadInt64(&l.pending) > 0
+}
+
+// Returns whether the executor was shut down
+func (l *lifecycle) isShutdown() bool {
+	return atomic.LoadInt32(&l.state) != stateRunning
+}
+
+// Returns whether every worker has exited after a shutdown
+func (l *lifecycle) isTerminated() bool {
+	return atomic.LoadInt32(&l.state) == stateTerminated
+}
+
+// Wait for every worker to exit
+func (l *lifecycle) wait() {
+	<-l.terminated
+}
+
+// Wait at most timeout for every worker to exit, returns whether they did
+func (l *lifecycle) await(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-l.terminated:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Returns the future of a task submitted after the executor was shut down
+func rejectedFuture(task interface{}) *future {
+	return failedFuture(task, ErrRejected)
+}
+
+// Remove every task that has not started yet from the injection queue and the local queues, along
+// with the tasks still waiting for their dependencies. Their futures resolve with ErrShutdown.
+// Returns the tasks as they were submitted.
+func drainQueues(l *lifecycle, injection DEQueue, queues []*localQueue) []interface{} {
+	tasks := l.drainWaiting()
+	drain := func(queue DEQueue) {
+		for job := queue.PopTop(); job != nil; job = queue.PopTop() {
+			// Tasks that were cancelled while waiting are not returned
+			if task, ok := job.(*future).withdraw(ErrShutdown); ok {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	drain(injection)
+	for _, queue := range queues {
+		drain(queue)
+	}
+	return tasks
+}
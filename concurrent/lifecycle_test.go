@@ -0,0 +1,121 @@
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Runnable counting its runs
+type countingTask struct {
+	runs *int64
+}
+
+func (task *countingTask) Run() {
+	atomic.AddInt64(task.runs, 1)
+}
+
+// Submissions racing Shutdown either run or are rejected, and the executor always terminates
+func TestConcurrentSubmit(t *testing.T) {
+	rounds := 20
+	if testing.Short() {
+		rounds = 4
+	}
+
+	for round := 0; round < rounds; round++ {
+		round := round
+		forEachExecutorService(t, 4, func(t *testing.T, executor ExecutorService) {
+			runs := int64(0)
+			submitted := make([][]Future, 8)
+
+			wg := &sync.WaitGroup{}
+			wg.Add(len(submitted))
+			for i := range submitted {
+				i := i
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 200; j++ {
+						submitted[i] = append(submitted[i], executor.Submit(&countingTask{runs: &runs}))
+					}
+				}()
+			}
+			// Shut down while the submissions are in flight or once they are all done
+			if round%2 == 0 {
+				executor.Shutdown()
+				wg.Wait()
+			} else {
+				wg.Wait()
+				executor.Shutdown()
+			}
+
+			extended := executor.(ExtendedExecutorService)
+			if !extended.IsShutdown() || !extended.IsTerminated() {
+				t.Fatal("executor did not terminate after Shutdown returned")
+			}
+			accepted := int64(0)
+			for _, futures := range submitted {
+				for _, future := range futures {
+					switch err := future.(ErrFuture).Err(); err {
+					case nil:
+						accepted++
+					case ErrRejected:
+					default:
+						t.Fatalf("submission failed with %v", err)
+					}
+				}
+			}
+			if accepted != atomic.LoadInt64(&runs) {
+				t.Fatalf("%d tasks were accepted but %d ran", accepted, runs)
+			}
+			if err := executor.Submit(&countingTask{runs: &runs}).(ErrFuture).Err(); err != ErrRejected {
+				t.Fatalf("submission after shutdown failed with %v", err)
+			}
+		})
+	}
+}
+
+// Runnable sleeping for a while
+type sleepingTask struct {
+	duration time.Duration
+}
+
+func (task *sleepingTask) Run() {
+	time.Sleep(task.duration)
+}
+
+func TestShutdownNow(t *testing.T) {
+	forEachExecutorService(t, 2, func(t *testing.T, executor ExecutorService) {
+		extended := executor.(ExtendedExecutorService)
+		futures := []Future{}
+		for i := 0; i < 50; i++ {
+			futures = append(futures, executor.Submit(&sleepingTask{duration: 20 * time.Millisecond}))
+		}
+		time.Sleep(5 * time.Millisecond)
+		if extended.IsShutdown() || extended.IsTerminated() {
+			t.Fatal("executor shut down before Shutdown")
+		}
+
+		// At most one task per worker has started
+		drained := extended.ShutdownNow()
+		if len(drained) < 40 || !extended.IsShutdown() {
+			t.Fatalf("drained %d tasks", len(drained))
+		}
+		if !extended.AwaitTermination(time.Second) || !extended.IsTerminated() {
+			t.Fatal("executor did not terminate")
+		}
+		cancelled := 0
+		for _, future := range futures {
+			switch err := future.(ErrFuture).Err(); err {
+			case ErrShutdown:
+				cancelled++
+			case nil:
+			default:
+				t.Fatalf("task failed with %v", err)
+			}
+		}
+		if cancelled != len(drained) {
+			t.Fatalf("%d tasks were drained but %d were cancelled", len(drained), cancelled)
+		}
+	})
+}
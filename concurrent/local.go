@@ -0,0 +1,110 @@
+package concurrent
+
+import (
+	"sync"
+)
+
+// localQueue is the local queue of a worker. Tasks handed over by other goroutines are
+// collected in a locked inbox and moved into the worker's deque by the owning worker so
+// that single-owner deques (e.g. ChaseLevDEQueue) are only ever pushed to by their owner.
+// (top) thieves / balancing -> deque -> owner (bottom)
+type localQueue struct {
+	deque   DEQueue     // Only pushed to and popped from at the bottom by the owning worker
+	inbox   DEQueue     // Tasks pushed by other goroutines
+	signal  *idleSignal // Wakes up the parked workers when a task is pushed
+	mu      sync.Mutex  // Guards forward
+	forward DEQueue     // Receives the tasks pushed once the owner has retired
+}
+
+// Returns a new localQueue backed by the given deque and inbox
+func newLocalQueue(deque, inbox DEQueue, signal *idleSignal) *localQueue {
+	return &localQueue{
+		deque:  deque,
+		inbox:  inbox,
+		signal: signal,
+	}
+}
+
+// PushBottom hands a task to the owning worker, it is safe to call from any goroutine
+func (q *localQueue) PushBottom(task Task) {
+	q.mu.Lock()
+	if q.forward != nil {
+		q.forward.PushBottom(task)
+	} else {
+		q.inbox.PushBottom(task)
+	}
+	q.mu.Unlock()
+	q.signal.notify()
+}
+
+// Moves every task into the given queue and forwards the tasks pushed from now on to it,
+// it must only be called by the owner once it has retired
+func (q *localQueue) retire(forward DEQueue) {
+	q.mu.Lock()
+	q.forward = forward
+	q.mu.Unlock()
+
+	// Keep the order of the tasks, oldest first
+	for task := q.PopTop(); task != nil; task = q.PopTop() {
+		forward.PushBottom(task)
+	}
+	q.signal.notify()
+}
+
+// Pushes a task onto the deque, it must only be called by the owner
+func (q *localQueue) pushLocal(task Task) {
+	q.deque.PushBottom(task)
+	q.signal.notify()
+}
+
+// PopBottom returns the next task of the owning worker, it must only be called by the owner
+func (q *localQueue) PopBottom() Task {
+	// Move the tasks handed over by other goroutines into the deque first so that they are
+	// ordered with the other tasks (by priority)
+	if q.inbox.Size() > 0 {
+		for task := q.inbox.PopTop(); task != nil; task = q.inbox.PopTop() {
+			q.deque.PushBottom(task)
+		}
+	}
+	return q.deque.PopBottom()
+}
+
+// Moves up to n tasks from the top of the source queue into the deque, it must only be called
+// by the owner. Returns the number of tasks moved.
+func (q *localQueue) grab(source DEQueue, n int) int {
+	moved := 0
+	for moved < n {
+		task := source.PopTop()
+		if task == nil {
+			break
+		}
+		q.deque.PushBottom(task)
+		moved++
+	}
+	return moved
+}
+
+// PopTop removes the oldest task of the highest priority, it is safe to call from any goroutine
+func (q *localQueue) PopTop() Task {
+	// The inbox holds the tasks the owner has not moved into the deque yet
+	if topPriority(q.inbox) > topPriority(q.deque) {
+		if task := q.inbox.PopTop(); task != nil {
+			return task
+		}
+	}
+	task := q.deque.PopTop()
+	if task != nil {
+		return task
+	}
+	return q.inbox.PopTop()
+}
+
+// IsEmpty returns whether the queue is empty
+func (q *localQueue) IsEmpty() bool {
+	return q.deque.IsEmpty() && q.inbox.IsEmpty()
+}
+
+// Size returns the size of the queue
+func (q *localQueue) Size() int {
+	return q.deque.Size() + q.inbox.Size()
+}
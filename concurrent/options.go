@@ -0,0 +1,165 @@
+package concurrent
+
+import (
+	"time"
+)
+
+// options holds the optional settings of the executors
+type options struct {
+	newDEQueue        func() DEQueue    // Creates the deque backing the local queue of each worker
+	idleStrategy      IdleStrategy      // What a worker does when it cannot find any work
+	stealPolicy       StealPolicy       // How many tasks a thief takes from its victim (work stealing only)
+	stealBatch        int               // Number of tasks stolen at once with StealBatch
+	newVictimSelector NewVictimSelector // Creates the victim selector of each worker
+	balancePolicy     BalancePolicy     // When and how the workers balance (work balancing only)
+	priorityLevels    int               // Number of priority levels of the tasks
+	autoScaling       *autoScaling      // Settings of the auto-scaler (nil if the number of workers is fixed)
+	seed              int64             // Seed all random decisions of the workers derive from
+	tracer            *Tracer           // Records the scheduling events of the workers (nil if not traced)
+	maxPending        int               // Maximum number of pending tasks (0 if unbounded)
+}
+
+// Option configures an optional setting of an executor
+type Option func(*options)
+
+// Returns the options with the defaults overridden by the given options
+func newOptions(opts []Option) *options {
+	config := &options{
+		newDEQueue:        NewUnBoundedDEQueue,
+		idleStrategy:      IdlePark,
+		stealPolicy:       StealOne,
+		stealBatch:        1,
+		newVictimSelector: NewRandomVictimSelector,
+		balancePolicy:     DefaultBalancePolicy(),
+		priorityLevels:    1,
+		autoScaling:       nil,
+		seed:              time.Now().UnixNano(),
+		tracer:            nil,
+		maxPending:        0,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// Returns a new queue created by newDEQueue, split into priority levels if there are several
+func (config *options) newQueue(newDEQueue func() DEQueue) DEQueue {
+	if config.priorityLevels > 1 {
+		return newPriorityDEQueue(config.priorityLevels, newDEQueue)
+	}
+	return newDEQueue()
+}
+
+// Returns a new queue for the submitted tasks that have not been grabbed by a worker yet
+func (config *options) newInjectionQueue() DEQueue {
+	return config.newQueue(NewUnBoundedDEQueue)
+}
+
+// Returns a new local queue of a worker
+func (config *options) newLocalQueue(signal *idleSignal) *localQueue {
+	return newLocalQueue(config.newQueue(config.newDEQueue), config.newQueue(NewUnBoundedDEQueue), signal)
+}
+
+// WithDEQueue selects the DEQueue implementation used for the local queues of the workers
+// (e.g. NewUnBoundedDEQueue or NewChaseLevDEQueue)
+func WithDEQueue(newDEQueue func() DEQueue) Option {
+	return func(config *options) {
+		config.newDEQueue = newDEQueue
+	}
+}
+
+// WithIdleStrategy selects what the workers do when they cannot find any work (IdlePark by default)
+func WithIdleStrategy(strategy IdleStrategy) Option {
+	return func(config *options) {
+		config.idleStrategy = strategy
+	}
+}
+
+// WithStealPolicy selects how many tasks a thief of the work-stealing executor takes from its
+// victim (StealOne by default), batch is the number of tasks stolen at once with StealBatch
+func WithStealPolicy(policy StealPolicy, batch int) Option {
+	return func(config *options) {
+		config.stealPolicy = policy
+		config.stealBatch = batch
+		// Steal at least one task at a time
+		if config.stealBatch < 1 {
+			config.stealBatch = 1
+		}
+	}
+}
+
+// WithVictimSelector selects how the workers choose the worker they steal from or balance with
+// (NewRandomVictimSelector by default)
+func WithVictimSelector(newVictimSelector NewVictimSelector) Option {
+	return func(config *options) {
+		config.newVictimSelector = newVictimSelector
+	}
+}
+
+// WithBalancePolicy selects when the workers of the work-balancing executor balance, with which
+// worker and how many tasks are moved (DefaultBalancePolicy by default)
+func WithBalancePolicy(policy BalancePolicy) Option {
+	return func(config *options) {
+		config.balancePolicy = policy
+	}
+}
+
+// WithPriorityLevels splits the queues of the executor into the given number of priority levels
+// (1 by default, i.e. all tasks are equal). Tasks are submitted with a priority through
+// SubmitPriority and the workers always run the tasks of the highest priority they can find first.
+func WithPriorityLevels(levels int) Option {
+	return func(config *options) {
+		config.priorityLevels = levels
+		// There is at least one level
+		if config.priorityLevels < 1 {
+			config.priorityLevels = 1
+		}
+	}
+}
+
+// WithAutoScaler resizes the executor every interval between minWorkers and maxWorkers workers:
+// a worker is added while more tasks are waiting than there are workers and a worker is retired
+// while no task is waiting and some workers are idle
+func WithAutoScaler(minWorkers, maxWorkers int, interval time.Duration) Option {
+	return func(config *options) {
+		// Keep at least one worker
+		if minWorkers < 1 {
+			minWorkers = 1
+		}
+		if maxWorkers < minWorkers {
+			maxWorkers = minWorkers
+		}
+		config.autoScaling = &autoScaling{
+			minWorkers: minWorkers,
+			maxWorkers: maxWorkers,
+			interval:   interval,
+		}
+	}
+}
+
+// WithSeed sets the seed all random decisions of the workers (e.g. choosing victims or when to
+// balance) derive from so that runs can be reproduced (a seed based on the time by default).
+// The interleaving of the workers still depends on the Go scheduler.
+func WithSeed(seed int64) Option {
+	return func(config *options) {
+		config.seed = seed
+	}
+}
+
+// WithTracer records the scheduling events of the workers (tasks run, steals, balance operations
+// and idle periods) with the given tracer (nil disables tracing, the default).
+func WithTracer(tracer *Tracer) Option {
+	return func(config *options) {
+		config.tracer = tracer
+	}
+}
+
+// WithMaxPending bounds the number of pending tasks (submitted through Submit, SubmitContext,
+// SubmitPriority or TrySubmit and not done yet) to limit (unbounded by default, as with limit < 1).
+// Once the bound is reached Submit blocks until a task is done and TrySubmit fails.
+func WithMaxPending(limit int) Option {
+	return func(config *options) {
+		config.maxPending = limit
+	}
+}
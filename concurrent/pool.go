@@ -0,0 +1,343 @@
+package concurrent
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Shared Context of the executors whose workers have local queues (work stealing and work balancing)
+type poolContext struct {
+	threshold         int       // Maximum number of tasks grabbed from the injection queue at once
+	injection         DEQueue   // Tasks submitted to the executor that have not been grabbed by a worker yet
+	queues            *queueSet // Local queues of the current workers
+	signal            *idleSignal
+	idleStrategy      IdleStrategy
+	newVictimSelector NewVictimSelector
+	seeds             *rand.Rand // Seeds the generators of the workers, only used while creating workers
+	tracer            *Tracer    // Records the scheduling events of the workers (nil if not traced)
+	priorityLevels    int        // Number of priority levels of the tasks
+	lifecycle         *lifecycle // Shared by the service and the workers
+	wg                *sync.WaitGroup
+}
+
+// Returns the shared context of a pool of capacity workers grabbing up to threshold tasks at once
+func newPoolContext(capacity, threshold int, config *options) *poolContext {
+	// Grab at least one task at a time
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	// Create capacity queues sharing the signal used to wake up idle workers
+	signal := newIdleSignal()
+	queues := []*localQueue{}
+	for i := 0; i < capacity; i++ {
+		queues = append(queues, config.newLocalQueue(signal))
+	}
+
+	return &poolContext{
+		threshold:         threshold,
+		injection:         config.newInjectionQueue(),
+		queues:            newQueueSet(queues),
+		signal:            signal,
+		idleStrategy:      config.idleStrategy,
+		newVictimSelector: config.newVictimSelector,
+		seeds:             rand.New(rand.NewSource(config.seed)),
+		tracer:            config.tracer,
+		priorityLevels:    config.priorityLevels,
+		lifecycle:         newLifecycle(signal),
+		wg:                &sync.WaitGroup{},
+	}
+}
+
+// Worker of a pool, the part of the work stealing and work balancing workers that does not
+// depend on how they share their work
+type poolWorker struct {
+	id       int
+	pool     *poolContext
+	randGen  *rand.Rand
+	victims  VictimSelector
+	idler    *idler
+	stats    *workerStats
+	forkJoin *forkJoinWorker // Used by the fork-join tasks run by the worker
+	queue    *localQueue     // Local queue of the worker, kept until it exits even if its id is reused by Resize
+	queues   []*localQueue   // Local queues of the workers, refreshed at every iteration
+	capacity int             // Number of workers in queues
+	retired  int32           // Set once the worker was removed by Resize
+}
+
+// Returns a new worker of the pool, its queue must have been published
+func newPoolWorker(id int, pool *poolContext) *poolWorker {
+	// Every worker gets its own generator seeded from the seed of the executor
+	randGen := rand.New(rand.NewSource(pool.seeds.Int63()))
+	queues := pool.queues.load()
+	worker := &poolWorker{
+		id:       id,
+		pool:     pool,
+		randGen:  randGen,
+		victims:  pool.newVictimSelector(id, len(queues), randGen),
+		idler:    newIdler(pool.idleStrategy, pool.signal),
+		stats:    newWorkerStats(id, pool.tracer),
+		queue:    queues[id],
+		queues:   queues,
+		capacity: len(queues),
+		retired:  0,
+	}
+	worker.forkJoin = newForkJoinWorker(id, worker.queue, queues, pool.injection, pool.signal, worker.victims, worker.stats)
+	return worker
+}
+
+// Pick up the queues of the workers added or retired since the last iteration
+func (worker *poolWorker) refresh() {
+	// A retiring worker keeps the queues it knows about until it exits, its id may already
+	// belong to a worker added since
+	if worker.isRetired() {
+		return
+	}
+	queues := worker.pool.queues.load()
+
+	// The victims are chosen among the current workers
+	if len(queues) != worker.capacity {
+		worker.capacity = len(queues)
+		worker.victims = worker.pool.newVictimSelector(worker.id, worker.capacity, worker.randGen)
+		worker.forkJoin.victims = worker.victims
+	}
+	worker.queues = queues
+	worker.forkJoin.queues = queues
+}
+
+// Remove the worker from the pool, it exits after its current task
+func (worker *poolWorker) retire() {
+	atomic.StoreInt32(&worker.retired, 1)
+}
+
+// Check if the worker was removed from the pool
+func (worker *poolWorker) isRetired() bool {
+	return atomic.LoadInt32(&worker.retired) == 1
+}
+
+// Check if the worker keeps looking for work, i.e. it was not retired and work is remaining in
+// the overall work pool or may still be submitted
+func (worker *poolWorker) running() bool {
+	return !worker.isRetired() && (worker.pool.lifecycle.open() || !worker.isWorkPoolEmpty())
+}
+
+// Check if all queues are empty
+func (worker *poolWorker) isWorkPoolEmpty() bool {
+	// Check if the injection queue and all local queues are empty
+	if !worker.pool.injection.IsEmpty() {
+		return false
+	}
+	for _, queue := range worker.queues {
+		if !queue.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the next victim from the victim selector
+func (worker *poolWorker) getVictim() int {
+	return worker.victims.Next(worker.queueSize)
+}
+
+// Size of the local queue of a worker
+func (worker *poolWorker) queueSize(id int) int {
+	return worker.queues[id].Size()
+}
+
+// Grab up to threshold tasks from the injection queue into the local queue
+func (worker *poolWorker) grab() int {
+	return worker.queue.grab(worker.pool.injection, worker.pool.threshold)
+}
+
+// Grab the submitted tasks first if they have a higher priority than the tasks of the local queue,
+// then steal a task of a higher priority still from another worker (a worker only steals once
+// its local queue is empty otherwise)
+func (worker *poolWorker) grabUrgent() {
+	if worker.pool.priorityLevels <= 1 {
+		return
+	}
+	if topPriority(worker.pool.injection) > topPriority(worker.queue) {
+		worker.grab()
+	}
+
+	// Find the most urgent task of the other workers
+	victim, urgent := -1, topPriority(worker.queue)
+	for id, queue := range worker.queues {
+		if queue == worker.queue {
+			continue
+		}
+		if priority := topPriority(queue); priority > urgent {
+			victim, urgent = id, priority
+		}
+	}
+	if victim >= 0 {
+		worker.stats.stealAttempt(victim, worker.queue.grab(worker.queues[victim], 1))
+	}
+}
+
+// End the routine of the worker once it stopped running
+func (worker *poolWorker) exit() {
+	// Hand the remaining tasks of a retired worker over to the other workers
+	if worker.isRetired() {
+		worker.queue.retire(worker.pool.injection)
+		worker.pool.lifecycle.release()
+	}
+
+	// Worker is done
+	worker.stats.working()
+	worker.pool.wg.Done()
+}
+
+// workerPool is the service of the executors whose workers have local queues, the workers can
+// be added and retired while it is running
+type workerPool struct {
+	*submitter
+	workers   []*poolWorker
+	context   *poolContext
+	config    *options                           // Used to create the queues of the workers added by Resize
+	newWorker func(id int) (*poolWorker, func()) // Creates the worker with the given id and returns its routine
+	lock      sync.Mutex                         // Guards the workers while resizing and the start of the shutdown
+}
+
+// Returns a new pool running capacity workers created by newWorker
+func newWorkerPool(capacity int, context *poolContext, config *options, newWorker func(id int) (*poolWorker, func())) *workerPool {
+	service := &workerPool{
+		submitter: newSubmitter(config, context.lifecycle, context.injection, context.signal),
+		workers:   []*poolWorker{},
+		context:   context,
+		config:    config,
+		newWorker: newWorker,
+	}
+
+	// Create and spawn capacity workers
+	for i := 0; i < capacity; i++ {
+		service.start(i)
+	}
+	context.lifecycle.watch(context.wg)
+
+	// Resize the executor depending on the load if asked to
+	if config.autoScaling != nil {
+		go autoScale(context.lifecycle, config.autoScaling, service, service.load)
+	}
+
+	return service
+}
+
+// Create the worker with the given id and spawn its routine
+func (service *workerPool) start(id int) {
+	worker, work := service.newWorker(id)
+	service.workers = append(service.workers, worker)
+	// Increment wait group (Decrement inside the work() method)
+	service.context.wg.Add(1)
+	go work()
+}
+
+// Stats returns a snapshot of the statistics of the workers
+func (service *workerPool) Stats() Stats {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	stats := Stats{Workers: []WorkerStats{}}
+	for _, worker := range service.workers {
+		stats.Workers = append(stats.Workers, worker.stats.snapshot())
+	}
+	return stats
+}
+
+// Resize changes the number of workers, the retired workers hand their queued tasks over to the
+// other workers once their current task completes. It is safe to call from any goroutine.
+func (service *workerPool) Resize(n int) {
+	// Keep at least one worker
+	if n < 1 {
+		n = 1
+	}
+
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	// The workers are exiting once the service is shut down
+	if service.context.lifecycle.isShutdown() {
+		return
+	}
+
+	current := len(service.workers)
+	queues := service.context.queues.load()
+	if n > current {
+		// Publish the queues of the new workers before they start
+		grown := append([]*localQueue{}, queues...)
+		for i := current; i < n; i++ {
+			grown = append(grown, service.config.newLocalQueue(service.context.signal))
+		}
+		service.context.queues.store(grown)
+
+		for i := current; i < n; i++ {
+			service.start(i)
+		}
+	} else if n < current {
+		// Retire the workers with the highest ids so that the ids of the others stay valid,
+		// the workers do not exit on shutdown before the retired workers handed their tasks over
+		for _, worker := range service.workers[n:] {
+			service.context.lifecycle.hold()
+			worker.retire()
+		}
+		service.context.queues.store(append([]*localQueue{}, queues[:n]...))
+		service.workers = append([]*poolWorker{}, service.workers[:n]...)
+	}
+
+	// Wake up the parked workers so that they pick up the new queues (or retire)
+	service.context.signal.notify()
+}
+
+// Workers returns the current number of workers
+func (service *workerPool) Workers() int {
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	return len(service.workers)
+}
+
+// Returns the number of tasks waiting in the queues and the number of idle workers
+func (service *workerPool) load() (int, int) {
+	backlog := service.context.injection.Size()
+	for _, queue := range service.context.queues.load() {
+		backlog += queue.Size()
+	}
+
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	idle := 0
+	for _, worker := range service.workers {
+		if worker.stats.isIdle() {
+			idle++
+		}
+	}
+	return backlog, idle
+}
+
+// Stop accepting tasks, the workers exit once the work pool is empty
+func (service *workerPool) initiateShutdown() {
+	// The workers are no longer resized once the shutdown has started
+	service.lock.Lock()
+	defer service.lock.Unlock()
+
+	service.context.lifecycle.shutdown()
+}
+
+// Shutdown the executor, it waits for all submitted tasks to run
+func (service *workerPool) Shutdown() {
+	service.initiateShutdown()
+
+	// Wait for all workers to finish
+	service.context.lifecycle.wait()
+}
+
+// ShutdownNow shuts down the executor without running the tasks that have not started yet
+func (service *workerPool) ShutdownNow() []interface{} {
+	service.initiateShutdown()
+
+	// Empty the work pool so that the workers exit after their current task
+	return drainQueues(service.context.lifecycle, service.context.injection, service.context.queues.load())
+}
@@ -0,0 +1,135 @@
+package concurrent
+
+import (
+	"testing"
+)
+
+// A worker grabs at most threshold submitted tasks at once, the oldest first
+func TestGrab(t *testing.T) {
+	tests := []struct {
+		threshold int
+		grabs     []int
+	}{
+		{3, []int{3, 3, 1, 0}},
+		{10, []int{7, 0}},
+		{0, []int{1, 1, 1, 1, 1, 1, 1, 0}},
+	}
+	for _, test := range tests {
+		pool := newPoolContext(2, test.threshold, newOptions(nil))
+		worker := newPoolWorker(0, pool)
+		for n := 0; n < 7; n++ {
+			pool.injection.PushBottom(newQueuedJob(n))
+		}
+
+		next := 0
+		for _, expected := range test.grabs {
+			if grabbed := worker.grab(); grabbed != expected {
+				t.Fatalf("grabbed %d tasks with threshold %d, expected %d", grabbed, test.threshold, expected)
+			}
+			for job := worker.queue.PopTop(); job != nil; job = worker.queue.PopTop() {
+				if n := job.(*future).task.(*squareTask).n; n != next {
+					t.Fatalf("grabbed task %d, expected task %d", n, next)
+				}
+				next++
+			}
+		}
+	}
+}
+
+// Runnable signalling once it runs and blocking its worker until the channel is closed
+type startedBlockingTask struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (task *startedBlockingTask) Run() {
+	close(task.started)
+	<-task.release
+}
+
+// Submitted tasks wait in the injection queue until a worker grabs them into its local queue
+func TestSubmitInjection(t *testing.T) {
+	executors := []struct {
+		name     string
+		executor ExecutorService
+		pool     func(executor ExecutorService) *workerPool
+	}{
+		{"ws", NewWorkStealingExecutor(1, 3), func(executor ExecutorService) *workerPool { return executor.(*stealer).workerPool }},
+		{"wb", NewWorkBalancingExecutor(1, 3, 2), func(executor ExecutorService) *workerPool { return executor.(*balancer).workerPool }},
+	}
+	for _, implementation := range executors {
+		executor, pool := implementation.executor, implementation.pool(implementation.executor)
+		t.Run(implementation.name, func(t *testing.T) {
+			// Keep the only worker busy
+			blocker := &startedBlockingTask{started: make(chan struct{}), release: make(chan struct{})}
+			blocked := executor.Submit(blocker)
+			<-blocker.started
+
+			futures := []Future{blocked}
+			for i := 0; i < 10; i++ {
+				futures = append(futures, executor.Submit(&squareTask{n: i}))
+			}
+			if size := pool.context.injection.Size(); size != 10 {
+				t.Fatalf("%d tasks are in the injection queue, expected 10", size)
+			}
+			if size := pool.context.queues.load()[0].Size(); size != 0 {
+				t.Fatalf("%d submitted tasks were pushed to the local queue", size)
+			}
+
+			close(blocker.release)
+			for _, future := range futures {
+				future.Get()
+			}
+			executor.Shutdown()
+			if depth := executor.(StatsExecutorService).Stats().Total().MaxQueueDepth; depth < 1 || depth > 3 {
+				t.Fatalf("the worker held up to %d tasks, expected at most the threshold of 3", depth)
+			}
+		})
+	}
+}
+
+// Returns the victims the workers of a pool choose and when they balance over a number of iterations
+func seededDecisions(seed int64) []int {
+	pool := newPoolContext(4, 1, newOptions([]Option{WithSeed(seed)}))
+	policy := DefaultBalancePolicy()
+	size := func(victim int) int { return victim }
+
+	decisions := []int{}
+	for id := 0; id < 4; id++ {
+		worker := newPoolWorker(id, pool)
+		for iteration := 1; iteration <= 50; iteration++ {
+			victim := worker.victims.Next(size)
+			worker.victims.Report(victim, iteration%3 == 0)
+			decisions = append(decisions, victim)
+			if policy.Trigger(iteration, iteration%5, worker.randGen) {
+				decisions = append(decisions, -iteration)
+			}
+		}
+	}
+	return decisions
+}
+
+// Two executors with the same seed (including 0) make the same victim and balance decisions
+func TestSeed(t *testing.T) {
+	for _, seed := range []int64{0, 42} {
+		first, second := seededDecisions(seed), seededDecisions(seed)
+		if len(first) != len(second) {
+			t.Fatalf("seed %d made %d and %d decisions", seed, len(first), len(second))
+		}
+		for i := range first {
+			if first[i] != second[i] {
+				t.Fatalf("seed %d made decisions %v and %v", seed, first, second)
+			}
+		}
+	}
+
+	// The decisions do derive from the seed
+	first, second := seededDecisions(0), seededDecisions(42)
+	differ := len(first) != len(second)
+	for i := 0; !differ && i < len(first); i++ {
+		differ = first[i] != second[i]
+	}
+	if !differ {
+		t.Fatal("seeds 0 and 42 made the same decisions")
+	}
+}
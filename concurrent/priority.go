@@ -0,0 +1,117 @@
+package concurrent
+
+// Priority of a task, tasks with a higher priority are run before tasks with a lower priority.
+// Priorities range from DefaultPriority (the lowest) to the number of priority levels of the
+// executor minus one (see WithPriorityLevels).
+type Priority int
+
+// DefaultPriority is the priority of the tasks submitted without a priority
+const DefaultPriority Priority = 0
+
+// Returns the priority clamped to the given number of levels
+func clampPriority(priority Priority, levels int) Priority {
+	if priority < DefaultPriority {
+		return DefaultPriority
+	}
+	if int(priority) >= levels {
+		return Priority(levels - 1)
+	}
+	return priority
+}
+
+// Returns the priority of a task in a queue
+func priorityOf(task Task) Priority {
+	if f, ok := task.(*future); ok {
+		return f.priority
+	}
+	return DefaultPriority
+}
+
+// priorityDEQueue keeps one deque per priority level, tasks are always popped from the highest
+// non-empty level (at the top and at the bottom) so that thieves take the most urgent tasks too.
+// Each level keeps the ownership rules of its deque.
+type priorityDEQueue struct {
+	levels []DEQueue // Indexed by priority
+}
+
+// Returns a new priorityDEQueue with the given number of levels each backed by a new deque
+func newPriorityDEQueue(levels int, newDEQueue func() DEQueue) *priorityDEQueue {
+	queue := &priorityDEQueue{levels: []DEQueue{}}
+	for i := 0; i < levels; i++ {
+		queue.levels = append(queue.levels, newDEQueue())
+	}
+	return queue
+}
+
+// PushBottom adds a task to the bottom of the level of its priority
+func (q *priorityDEQueue) PushBottom(task Task) {
+	q.levels[clampPriority(priorityOf(task), len(q.levels))].PushBottom(task)
+}
+
+// PopBottom removes the newest task of the highest non-empty level
+func (q *priorityDEQueue) PopBottom() Task {
+	for level := len(q.levels) - 1; level >= 0; level-- {
+		if task := q.levels[level].PopBottom(); task != nil {
+			return task
+		}
+	}
+	return nil
+}
+
+// PopTop removes the oldest task of the highest non-empty level
+func (q *priorityDEQueue) PopTop() Task {
+	for level := len(q.levels) - 1; level >= 0; level-- {
+		if task := q.levels[level].PopTop(); task != nil {
+			return task
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns whether every level is empty
+func (q *priorityDEQueue) IsEmpty() bool {
+	for _, level := range q.levels {
+		if !level.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns the number of tasks over all levels
+func (q *priorityDEQueue) Size() int {
+	size := 0
+	for _, level := range q.levels {
+		size += level.Size()
+	}
+	return size
+}
+
+// Returns the highest priority of the tasks in the queue (-1 if the queue is empty)
+func (q *priorityDEQueue) top() Priority {
+	for level := len(q.levels) - 1; level >= 0; level-- {
+		if !q.levels[level].IsEmpty() {
+			return Priority(level)
+		}
+	}
+	return -1
+}
+
+// Returns the highest priority of the tasks in any queue (-1 if the queue is empty)
+func topPriority(queue DEQueue) Priority {
+	switch q := queue.(type) {
+	case *priorityDEQueue:
+		return q.top()
+	case *localQueue:
+		deque, inbox := topPriority(q.deque), topPriority(q.inbox)
+		if inbox > deque {
+			return inbox
+		}
+		return deque
+	}
+
+	if queue.IsEmpty() {
+		return -1
+	}
+	return DefaultPriority
+}
@@ -0,0 +1,99 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// Runnable recording its id once it runs
+type recordingTask struct {
+	id    int
+	mu    *sync.Mutex
+	order *[]int
+}
+
+func (task *recordingTask) Run() {
+	task.mu.Lock()
+	*task.order = append(*task.order, task.id)
+	task.mu.Unlock()
+}
+
+// Queued tasks of a higher priority run before the tasks of a lower priority submitted earlier
+func TestPriority(t *testing.T) {
+	executors := []struct {
+		name     string
+		executor ExecutorService
+	}{
+		{"ws", NewWorkStealingExecutor(1, 1, WithPriorityLevels(3))},
+		{"ws-chaselev", NewWorkStealingExecutor(1, 1, WithPriorityLevels(3), WithDEQueue(NewChaseLevDEQueue))},
+		{"wb", NewWorkBalancingExecutor(1, 1, 2, WithPriorityLevels(3))},
+		{"wsh", NewWorkSharingExecutor(1, WithPriorityLevels(3))},
+	}
+	for _, implementation := range executors {
+		executor := implementation.executor
+		t.Run(implementation.name, func(t *testing.T) {
+			defer executor.Shutdown()
+			prioritized := executor.(PriorityExecutorService)
+
+			// Keep the only worker busy until every task is queued
+			blocker := &blockingTask{release: make(chan struct{})}
+			executor.Submit(blocker)
+
+			mu := &sync.Mutex{}
+			order := []int{}
+			futures := []Future{}
+			for i := 0; i < 20; i++ {
+				futures = append(futures, executor.Submit(&recordingTask{id: 0, mu: mu, order: &order}))
+			}
+			// Priorities past the highest level are clamped to it
+			futures = append(futures, prioritized.SubmitPriority(context.Background(), &recordingTask{id: 1, mu: mu, order: &order}, 1))
+			futures = append(futures, prioritized.SubmitPriority(context.Background(), &recordingTask{id: 2, mu: mu, order: &order}, 99))
+			close(blocker.release)
+			for _, future := range futures {
+				future.Get()
+			}
+
+			if len(order) != 22 || order[0] != 2 || order[1] != 1 {
+				t.Fatalf("tasks ran in order %v, expected the highest priorities first", order)
+			}
+		})
+	}
+}
+
+// Returns a queued job of the given priority
+func prioritizedJob(id int, priority Priority) *future {
+	job := newFuture(context.Background(), &recordingTask{id: id})
+	job.priority = priority
+	return job
+}
+
+// A busy worker steals the tasks of the other workers that are more urgent than its own
+func TestGrabUrgent(t *testing.T) {
+	pool := newPoolContext(3, 1, newOptions([]Option{WithPriorityLevels(3)}))
+	worker := newPoolWorker(0, pool)
+	queues := pool.queues.load()
+
+	// Equally urgent tasks are left to their workers
+	worker.queue.PushBottom(prioritizedJob(0, 1))
+	queues[1].PushBottom(prioritizedJob(1, 1))
+	worker.grabUrgent()
+	if worker.queue.Size() != 1 || queues[1].Size() != 1 {
+		t.Fatal("worker stole a task that was not more urgent than its own")
+	}
+
+	// The most urgent task is taken even from behind the less urgent tasks of the victim
+	queues[1].pushLocal(prioritizedJob(2, 0))
+	queues[1].PushBottom(prioritizedJob(3, 2))
+	queues[2].PushBottom(prioritizedJob(4, 1))
+	worker.grabUrgent()
+	if job, ok := worker.queue.PopBottom().(*future); !ok || job.task.(*recordingTask).id != 3 {
+		t.Fatalf("worker ran %v before the most urgent task of the other workers", job)
+	}
+	if queues[1].Size() != 2 || queues[2].Size() != 1 {
+		t.Fatal("worker stole more than the urgent task")
+	}
+	if stats := worker.stats.snapshot(); stats.Steals != 1 || stats.TasksStolen != 1 {
+		t.Fatalf("worker recorded %d steals of %d tasks, expected 1 of 1", stats.Steals, stats.TasksStolen)
+	}
+}
@@ -0,0 +1,65 @@
+package concurrent
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// queueSet holds the local queues of the current workers of an executor. The slice is never
+// modified, resizing the executor stores a new one.
+type queueSet struct {
+	value atomic.Value
+}
+
+// Returns a new queueSet holding the given queues
+func newQueueSet(queues []*localQueue) *queueSet {
+	set := &queueSet{}
+	set.store(queues)
+	return set
+}
+
+// Returns the current queues
+func (set *queueSet) load() []*localQueue {
+	return set.value.Load().([]*localQueue)
+}
+
+// Replace the current queues
+func (set *queueSet) store(queues []*localQueue) {
+	set.value.Store(queues)
+}
+
+// Settings of the auto-scaler of an executor
+type autoScaling struct {
+	minWorkers int
+	maxWorkers int
+	interval   time.Duration // Time between two scaling decisions
+}
+
+// resizable is an executor whose workers can be counted and resized
+type resizable interface {
+	Resize(n int)
+	Workers() int
+}
+
+// Scale the executor every interval until it is shut down: a worker is added when there are more
+// tasks waiting than workers and a worker is retired when no task is waiting and some workers are idle.
+// load returns the number of waiting tasks and the number of idle workers.
+func autoScale(l *lifecycle, scaling *autoScaling, service resizable, load func() (backlog, idle int)) {
+	ticker := time.NewTicker(scaling.interval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		if l.isShutdown() {
+			return
+		}
+
+		workers := service.Workers()
+		backlog, idle := load()
+		if backlog > workers && workers < scaling.maxWorkers {
+			service.Resize(workers + 1)
+		} else if backlog == 0 && idle > 0 && workers > scaling.minWorkers {
+			service.Resize(workers - 1)
+		}
+	}
+}
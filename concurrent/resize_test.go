@@ -0,0 +1,142 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The executors that can be resized, each is created with the given number of workers
+var resizableImplementations = []struct {
+	name        string
+	newExecutor func(capacity int) ExecutorService
+}{
+	{"ws", func(capacity int) ExecutorService {
+		return NewWorkStealingExecutor(capacity, 2, WithDEQueue(NewChaseLevDEQueue), WithStealPolicy(StealHalf, 1))
+	}},
+	{"wb", func(capacity int) ExecutorService {
+		return NewWorkBalancingExecutor(capacity, 2, 1,
+			WithBalancePolicy(NewBalancePolicy(TriggerOnEmpty(), PairMostImbalanced(), MoveUntilEqual())))
+	}},
+}
+
+// Runnable yielding the processor a few times before counting its run so that the workers
+// interleave with the resizing
+type yieldingTask struct {
+	runs *int64
+}
+
+func (task *yieldingTask) Run() {
+	for i := 0; i < 10; i++ {
+		runtime.Gosched()
+	}
+	atomic.AddInt64(task.runs, 1)
+}
+
+// Every task runs exactly once while the executor grows and shrinks
+func TestResize(t *testing.T) {
+	rounds := 5
+	if testing.Short() {
+		rounds = 1
+	}
+
+	for _, implementation := range resizableImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			for round := 0; round < rounds; round++ {
+				executor := implementation.newExecutor(2)
+				resizable := executor.(ResizableExecutorService)
+				runs := int64(0)
+				futures := []Future{}
+				for i := 0; i < 2000; i++ {
+					futures = append(futures, executor.Submit(&yieldingTask{runs: &runs}))
+					switch i % 300 {
+					case 0:
+						resizable.Resize(8)
+					case 100:
+						resizable.Resize(1)
+					case 200:
+						resizable.Resize(3)
+					}
+				}
+				resizable.Resize(1)
+				executor.Shutdown()
+
+				if runs != 2000 || resizable.Workers() != 1 {
+					t.Fatalf("%d tasks ran on %d workers, expected 2000 tasks on 1 worker", runs, resizable.Workers())
+				}
+				for _, future := range futures {
+					if err := future.(ErrFuture).Err(); err != nil {
+						t.Fatalf("task failed with %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// A retired worker keeps its own queue once its id is given to a new worker
+func TestResizeReusedId(t *testing.T) {
+	service := NewWorkStealingExecutor(2, 1).(*stealer)
+	defer service.Shutdown()
+
+	retired := service.workers[1]
+	service.Resize(1)
+	service.Resize(2)
+	added := service.workers[1]
+	if added == retired || added.queue == retired.queue {
+		t.Fatal("the worker added by Resize reused the retired worker or its queue")
+	}
+
+	// Wait for the retired worker to hand its queue over before looking at its queues
+	for {
+		retired.queue.mu.Lock()
+		forwarded := retired.queue.forward != nil
+		retired.queue.mu.Unlock()
+		if forwarded {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	retired.refresh()
+	for _, queue := range retired.queues {
+		if queue == added.queue {
+			t.Fatal("the retired worker picked up the queue of the worker that reused its id")
+		}
+	}
+
+	// The new worker still runs tasks
+	checkSquares(t, service, 100)
+}
+
+func TestAutoScale(t *testing.T) {
+	executor := NewWorkStealingExecutor(1, 1, WithAutoScaler(1, 6, time.Millisecond))
+	defer executor.Shutdown()
+	resizable := executor.(ResizableExecutorService)
+
+	// A single worker needs a second to run the backlog
+	futures := []Future{}
+	for i := 0; i < 1000; i++ {
+		futures = append(futures, executor.Submit(&sleepingTask{duration: time.Millisecond}))
+	}
+	deadline := time.Now().Add(time.Second)
+	for resizable.Workers() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if resizable.Workers() < 2 {
+		t.Fatal("the executor did not grow with a backlog of tasks")
+	}
+	for _, future := range futures {
+		future.Get()
+	}
+
+	// Idle workers are retired one per interval
+	deadline = time.Now().Add(time.Second)
+	for resizable.Workers() > 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if workers := resizable.Workers(); workers != 1 {
+		t.Fatalf("%d workers are left once the executor is idle, expected 1", workers)
+	}
+}
@@ -0,0 +1,342 @@
+package concurrent
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCancelled is the error of the Future of a scheduled task that was cancelled
+var ErrCancelled = errors.New("concurrent: scheduled task was cancelled")
+
+// Time after which a due task is handed over again when the executor had no free slot for it
+const scheduledRetryDelay = time.Millisecond
+
+// scheduledFuture is the Future of a scheduled task. It resolves with the result of the task for a
+// delayed task and once the task stops running (cancelled, failed or shut down) for a periodic task.
+type scheduledFuture struct {
+	*future
+	job       interface{}   // The task handed to the executor each time it is due
+	period    time.Duration // Time between two runs (0 for a delayed task)
+	scheduler *scheduler
+	at        time.Time // When the task is due next, guarded by the scheduler
+	seq       uint64    // Orders the tasks due at the same time by when they were scheduled
+	index     int       // Index in the queue of the scheduler (-1 if not queued), guarded by the scheduler
+	running   Future    // The current run handed to the executor (nil if none), guarded by the scheduler
+}
+
+// Cancel removes the task from the schedule, a run that was handed to the executor is skipped
+// unless it has already started. Returns whether the task was cancelled before running to
+// completion (always for a periodic task that was still scheduled).
+func (f *scheduledFuture) Cancel() bool {
+	s := f.scheduler
+	s.mu.Lock()
+	if f.index >= 0 {
+		heap.Remove(&s.queue, f.index)
+	}
+	// A task waiting for its delay (or any periodic task) is withdrawn right away, the scheduler
+	// hands a run over while holding the lock so that either the run is known here or none follows
+	withdrawn := atomic.CompareAndSwapInt32(&f.future.state, futurePending, futureDone)
+	running := f.running
+	s.mu.Unlock()
+
+	if withdrawn {
+		f.future.complete(nil, ErrCancelled)
+	}
+
+	// Skip the current run if it is still queued in the executor, the future of a delayed
+	// task then resolves with ErrCancelled once the run is relayed
+	if run, ok := running.(*future); ok {
+		if _, ok := run.withdraw(ErrCancelled); ok {
+			withdrawn = true
+		}
+	}
+	return withdrawn
+}
+
+// Delay returns the time left until the task is due next (0 once it is no longer scheduled)
+func (f *scheduledFuture) Delay() time.Duration {
+	s := f.scheduler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.index < 0 {
+		return 0
+	}
+	if delay := time.Until(f.at); delay > 0 {
+		return delay
+	}
+	return 0
+}
+
+// Min-heap of the scheduled tasks ordered by when they are due
+type scheduledQueue []*scheduledFuture
+
+func (q scheduledQueue) Len() int { return len(q) }
+
+func (q scheduledQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q scheduledQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduledQueue) Push(x interface{}) {
+	task := x.(*scheduledFuture)
+	task.index = len(*q)
+	*q = append(*q, task)
+}
+
+func (q *scheduledQueue) Pop() interface{} {
+	old := *q
+	task := old[len(old)-1]
+	old[len(old)-1] = nil
+	task.index = -1
+	*q = old[:len(old)-1]
+	return task
+}
+
+// scheduler hands the scheduled tasks over to an executor once they are due. A single goroutine
+// waits for the earliest task of a heap instead of one timer per task.
+type scheduler struct {
+	executor ExecutorService
+	mu       sync.Mutex // Guards the queue, seq and stopped along with the scheduling fields of the tasks
+	queue    scheduledQueue
+	seq      uint64
+	stopped  bool
+	wake     chan struct{} // Wakes up the timer goroutine when the earliest task changes
+	done     chan struct{} // Closed once the timer goroutine has exited
+}
+
+// NewScheduledExecutor returns a ScheduledExecutorService that runs the scheduled tasks on the
+// given executor once they are due. Submit hands the task over to the executor right away and
+// Shutdown also shuts down the executor. A bounded executor (see WithMaxPending) is handed the due
+// tasks with TrySubmit, a task finding it full is due again shortly after.
+// @param executor - The executor running the tasks (e.g. NewWorkStealingExecutor), it is
+// shared with the tasks submitted to it directly
+func NewScheduledExecutor(executor ExecutorService) ScheduledExecutorService {
+	s := &scheduler{
+		executor: executor,
+		queue:    scheduledQueue{},
+		seq:      0,
+		stopped:  false,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Submit hands a task over to the executor right away
+func (s *scheduler) Submit(task interface{}) Future {
+	return s.executor.Submit(task)
+}
+
+// Schedule runs a task once after the delay
+func (s *scheduler) Schedule(task interface{}, delay time.Duration) ScheduledFuture {
+	return s.schedule(task, delay, 0)
+}
+
+// ScheduleAtFixedRate runs a task after the initial delay and then every period
+func (s *scheduler) ScheduleAtFixedRate(task interface{}, initialDelay, period time.Duration) ScheduledFuture {
+	if period <= 0 {
+		panic("concurrent: non-positive period for ScheduleAtFixedRate")
+	}
+	return s.schedule(task, initialDelay, period)
+}
+
+// Queue a task due after the delay
+func (s *scheduler) schedule(task interface{}, delay time.Duration, period time.Duration) ScheduledFuture {
+	f := &scheduledFuture{
+		future:    newFuture(context.Background(), task),
+		job:       task,
+		period:    period,
+		scheduler: s,
+		at:        time.Now().Add(delay),
+		index:     -1,
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		f.future.cancel(ErrRejected)
+		return f
+	}
+	s.push(f)
+	s.mu.Unlock()
+	return f
+}
+
+// Queue a task and wake up the timer goroutine if it is the earliest one, the lock must be held
+func (s *scheduler) push(f *scheduledFuture) {
+	s.seq++
+	f.seq = s.seq
+	heap.Push(&s.queue, f)
+
+	if f.index == 0 {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Hand the tasks over to the executor once they are due until the scheduler is shut down
+func (s *scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		// Take the tasks that are due and find out how long to wait for the next one
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		due := []*scheduledFuture{}
+		for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			due = append(due, heap.Pop(&s.queue).(*scheduledFuture))
+		}
+		wait := time.Duration(-1)
+		if len(s.queue) > 0 {
+			wait = s.queue[0].at.Sub(now)
+		}
+		s.mu.Unlock()
+
+		for _, f := range due {
+			s.run(f)
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		// Sleep until the earliest task is due or the schedule changes
+		if wait < 0 {
+			<-s.wake
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// Hand a due task over to the executor and relay the result of the run. The timer goroutine does
+// not wait for a slot of a bounded executor, the task is due again shortly after instead.
+func (s *scheduler) run(f *scheduledFuture) {
+	s.mu.Lock()
+	// A task taken from the queue before a shutdown is not in the tasks Shutdown cancels
+	if s.stopped {
+		s.mu.Unlock()
+		f.future.withdraw(ErrShutdown)
+		return
+	}
+	// A delayed task is only run once and not if it was cancelled meanwhile
+	if f.period == 0 && !atomic.CompareAndSwapInt32(&f.future.state, futurePending, futureRunning) {
+		s.mu.Unlock()
+		return
+	}
+	if f.period > 0 && atomic.LoadInt32(&f.future.state) != futurePending {
+		s.mu.Unlock()
+		return
+	}
+
+	run, ok := s.handOver(f.job)
+	if !ok {
+		if f.period == 0 {
+			atomic.StoreInt32(&f.future.state, futurePending)
+		}
+		f.at = time.Now().Add(scheduledRetryDelay)
+		s.push(f)
+		s.mu.Unlock()
+		return
+	}
+	f.running = run
+	s.mu.Unlock()
+
+	whenDone(run, func(err error) {
+		s.finish(f, run, err)
+	})
+}
+
+// Submit a task to the executor unless it is bounded and has no free slot, the lock must be held
+func (s *scheduler) handOver(task interface{}) (Future, bool) {
+	if bounded, ok := s.executor.(BoundedExecutorService); ok {
+		return bounded.TrySubmit(task)
+	}
+	return s.executor.Submit(task), true
+}
+
+// Resolve the future of a delayed task with the result of its run, or schedule the next run of
+// a periodic task
+func (s *scheduler) finish(f *scheduledFuture, run Future, err error) {
+	s.mu.Lock()
+	f.running = nil
+
+	if f.period == 0 {
+		s.mu.Unlock()
+		f.future.complete(run.Get(), err)
+		return
+	}
+
+	// A periodic task stops running once a run fails (e.g. panics) or the scheduler is shut down
+	if err != nil || s.stopped {
+		s.mu.Unlock()
+		if err == nil {
+			err = ErrShutdown
+		}
+		f.future.withdraw(err)
+		return
+	}
+
+	// The next run is due one period after the previous one was due (right away if the run took
+	// longer than the period) unless the task was cancelled meanwhile
+	if atomic.LoadInt32(&f.future.state) == futurePending {
+		f.at = f.at.Add(f.period)
+		s.push(f)
+	}
+	s.mu.Unlock()
+}
+
+// Shutdown cancels the tasks that were not handed over yet (their Futures resolve with ErrShutdown), stops
+// the periodic tasks and shuts down the executor, which runs the tasks already handed over to it.
+// Tasks scheduled afterwards are rejected.
+func (s *scheduler) Shutdown() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		s.executor.Shutdown()
+		return
+	}
+	s.stopped = true
+	queued := s.queue
+	for _, f := range queued {
+		f.index = -1
+	}
+	s.queue = scheduledQueue{}
+	s.mu.Unlock()
+
+	// Stop the timer goroutine
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	<-s.done
+
+	for _, f := range queued {
+		f.future.withdraw(ErrShutdown)
+	}
+	s.executor.Shutdown()
+}
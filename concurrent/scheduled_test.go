@@ -0,0 +1,306 @@
+package concurrent
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Runnable counting its runs and sending the time each run starts, unless nobody is waiting
+type tickingTask struct {
+	runs  *int64
+	ticks chan time.Time
+}
+
+func (task *tickingTask) Run() {
+	atomic.AddInt64(task.runs, 1)
+	select {
+	case task.ticks <- time.Now():
+	default:
+	}
+}
+
+// Waits for the next run of a tickingTask
+func awaitTick(t *testing.T, ticks chan time.Time) time.Time {
+	select {
+	case at := <-ticks:
+		return at
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduled task did not run")
+		return time.Time{}
+	}
+}
+
+// The delayed tasks run in the order they are due unless they were cancelled
+func TestSchedule(t *testing.T) {
+	// A single worker runs the tasks in the order they are handed over
+	scheduled := NewScheduledExecutor(NewWorkSharingExecutor(1))
+	defer scheduled.Shutdown()
+
+	mu := &sync.Mutex{}
+	order := []int{}
+	futures := []ScheduledFuture{}
+	for _, delay := range []int{50, 10, 30, 20, 40} {
+		task := &recordingTask{id: delay, mu: mu, order: &order}
+		futures = append(futures, scheduled.Schedule(task, time.Duration(delay)*time.Millisecond))
+	}
+	cancelled := scheduled.Schedule(&recordingTask{id: 0, mu: mu, order: &order}, time.Hour)
+	if delay := cancelled.Delay(); delay <= 0 || delay > time.Hour {
+		t.Fatalf("task is due in %v, expected at most an hour", delay)
+	}
+	if !cancelled.Cancel() {
+		t.Fatal("queued task was not cancelled")
+	}
+	if cancelled.Cancel() {
+		t.Fatal("task was cancelled twice")
+	}
+	if err := cancelled.Err(); err != ErrCancelled {
+		t.Fatalf("cancelled task failed with %v", err)
+	}
+	if cancelled.Delay() != 0 {
+		t.Fatal("cancelled task is still due")
+	}
+
+	// A delayed Callable resolves with its value
+	if value := scheduled.Schedule(&squareTask{n: 6}, 5*time.Millisecond).Get(); value != 36 {
+		t.Fatalf("task returned %v, expected 36", value)
+	}
+
+	for _, future := range futures {
+		if err := future.Err(); err != nil {
+			t.Fatalf("delayed task failed with %v", err)
+		}
+	}
+	if futures[0].Delay() != 0 {
+		t.Fatal("task that ran is still due")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []int{10, 20, 30, 40, 50}
+	if len(order) != len(expected) {
+		t.Fatalf("tasks ran in order %v, expected %v", order, expected)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("tasks ran in order %v, expected %v", order, expected)
+		}
+	}
+}
+
+// A periodic task runs every period until it is cancelled or a run fails
+func TestScheduleAtFixedRate(t *testing.T) {
+	scheduled := NewScheduledExecutor(NewWorkStealingExecutor(2, 1))
+
+	failing := scheduled.ScheduleAtFixedRate(&panickingTask{message: "boom"}, 0, time.Millisecond)
+	if _, ok := failing.Err().(*PanicError); !ok {
+		t.Fatalf("panicking periodic task failed with %v", failing.Err())
+	}
+
+	// The runs are due one period apart, counted from the first time the task was due
+	period := 5 * time.Millisecond
+	runs := int64(0)
+	ticks := make(chan time.Time, 1)
+	start := time.Now()
+	periodic := scheduled.ScheduleAtFixedRate(&tickingTask{runs: &runs, ticks: ticks}, 0, period)
+	last := start
+	for i := 0; i < 5; i++ {
+		last = awaitTick(t, ticks)
+	}
+	if elapsed := last.Sub(start); elapsed < 4*period {
+		t.Fatalf("fifth run started %v after the task was scheduled, expected at least %v", elapsed, 4*period)
+	}
+
+	if !periodic.Cancel() {
+		t.Fatal("periodic task was not cancelled")
+	}
+	if err := periodic.Err(); err != ErrCancelled {
+		t.Fatalf("cancelled periodic task failed with %v", err)
+	}
+
+	// Only the run in progress when it was cancelled may still complete
+	ran := atomic.LoadInt64(&runs)
+	scheduled.Shutdown()
+	if atomic.LoadInt64(&runs) > ran+1 {
+		t.Fatal("periodic task kept running after it was cancelled")
+	}
+}
+
+// Shutdown cancels the queued and periodic tasks, the tasks scheduled afterwards are rejected
+func TestScheduledShutdown(t *testing.T) {
+	scheduled := NewScheduledExecutor(NewWorkStealingExecutor(2, 1))
+
+	// The tasks share the timer goroutine
+	runs := int64(0)
+	before := runtime.NumGoroutine()
+	queued := []ScheduledFuture{}
+	for i := 0; i < 1000; i++ {
+		queued = append(queued, scheduled.Schedule(&countingTask{runs: &runs}, time.Hour))
+	}
+	if after := runtime.NumGoroutine(); after > before+5 {
+		t.Fatalf("%d goroutines were started for the scheduled tasks", after-before)
+	}
+
+	ticks := make(chan time.Time, 1)
+	periodic := scheduled.ScheduleAtFixedRate(&tickingTask{runs: &runs, ticks: ticks}, 0, time.Millisecond)
+	awaitTick(t, ticks)
+	scheduled.Shutdown()
+	for _, future := range queued {
+		if err := future.Err(); err != ErrShutdown {
+			t.Fatalf("queued task failed with %v", err)
+		}
+	}
+	if err := periodic.Err(); err != ErrShutdown {
+		t.Fatalf("periodic task failed with %v", err)
+	}
+	if err := scheduled.Schedule(&countingTask{runs: &runs}, 0).Err(); err != ErrRejected {
+		t.Fatalf("task scheduled after the shutdown failed with %v", err)
+	}
+}
+
+// A task handed over to the executor is skipped when it is cancelled before it starts, also when
+// Cancel races the hand over
+func TestScheduledCancelHandedOver(t *testing.T) {
+	executor := NewWorkSharingExecutor(1)
+	scheduled := NewScheduledExecutor(executor)
+	defer scheduled.Shutdown()
+
+	// Keep the only worker busy so that no run can start
+	blocker := &blockingTask{release: make(chan struct{})}
+	executor.Submit(blocker)
+	defer close(blocker.release)
+
+	runs := int64(0)
+	for i := 0; i < 500; i++ {
+		// Cancel the task at different points of the hand over
+		future := scheduled.Schedule(&countingTask{runs: &runs}, 0)
+		for spin := 0; spin < i%50; spin++ {
+			runtime.Gosched()
+		}
+		if !future.Cancel() {
+			t.Fatalf("task %d was not cancelled before it started", i)
+		}
+		if err := future.Err(); err != ErrCancelled {
+			t.Fatalf("cancelled task failed with %v", err)
+		}
+	}
+	if ran := atomic.LoadInt64(&runs); ran != 0 {
+		t.Fatalf("%d cancelled tasks ran", ran)
+	}
+}
+
+// BoundedExecutorService signalling the submissions it rejected because it was full
+type fullSignalingExecutor struct {
+	BoundedExecutorService
+	full chan struct{}
+}
+
+func (executor *fullSignalingExecutor) TrySubmit(task interface{}) (Future, bool) {
+	future, ok := executor.BoundedExecutorService.TrySubmit(task)
+	if !ok {
+		select {
+		case executor.full <- struct{}{}:
+		default:
+		}
+	}
+	return future, ok
+}
+
+// The timer goroutine does not wait for a slot of a bounded executor, the due tasks are handed
+// over once a slot is free
+func TestScheduledBounded(t *testing.T) {
+	for _, implementation := range boundedImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			executor := &fullSignalingExecutor{
+				BoundedExecutorService: implementation.newExecutor().(BoundedExecutorService),
+				full:                   make(chan struct{}),
+			}
+			scheduled := NewScheduledExecutor(executor)
+			defer scheduled.Shutdown()
+
+			// Take every slot of the bound
+			blocker := &blockingTask{release: make(chan struct{})}
+			executor.Submit(blocker)
+			executor.Submit(blocker)
+
+			// The task is handed over again after it found the executor full
+			runs := int64(0)
+			waiting := scheduled.Schedule(&countingTask{runs: &runs}, 0)
+			for attempt := 0; attempt < 2; attempt++ {
+				select {
+				case <-executor.full:
+				case <-time.After(5 * time.Second):
+					t.Fatal("due task was not handed over")
+				}
+			}
+			if !waiting.Cancel() {
+				t.Fatal("task waiting for a slot was not cancelled")
+			}
+			if err := waiting.Err(); err != ErrCancelled {
+				t.Fatalf("cancelled task failed with %v", err)
+			}
+
+			// The timer goroutine keeps handing the tasks over once a slot is free
+			squared := scheduled.Schedule(&squareTask{n: 7}, 0)
+			select {
+			case <-executor.full:
+			case <-time.After(5 * time.Second):
+				t.Fatal("due task was not handed over")
+			}
+			close(blocker.release)
+			if value, err := squared.GetTimeout(5 * time.Second); value != 49 || err != nil {
+				t.Fatalf("task returned %v, %v, expected 49", value, err)
+			}
+			if ran := atomic.LoadInt64(&runs); ran != 0 {
+				t.Fatal("cancelled task ran")
+			}
+		})
+	}
+}
+
+// Shutdown cancels the due tasks waiting for a slot of a bounded executor, also the ones the timer
+// goroutine is about to hand over
+func TestScheduledBoundedShutdown(t *testing.T) {
+	for _, implementation := range boundedImplementations {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			for round := 0; round < 50; round++ {
+				executor := implementation.newExecutor()
+				scheduled := NewScheduledExecutor(executor)
+
+				// Take every slot of the bound so that the due tasks are retried
+				blocker := &blockingTask{release: make(chan struct{})}
+				executor.Submit(blocker)
+				executor.Submit(blocker)
+
+				runs := int64(0)
+				futures := []ScheduledFuture{}
+				for i := 0; i < 10; i++ {
+					futures = append(futures, scheduled.Schedule(&countingTask{runs: &runs}, 0))
+				}
+				for spin := 0; spin < round; spin++ {
+					runtime.Gosched()
+				}
+
+				// The executor only terminates once the blocker is released
+				stopped := make(chan struct{})
+				go func() {
+					scheduled.Shutdown()
+					close(stopped)
+				}()
+				for i, future := range futures {
+					if _, err := future.GetTimeout(5 * time.Second); err != ErrShutdown {
+						t.Fatalf("round %d: task %d waiting for a slot failed with %v", round, i, err)
+					}
+				}
+				close(blocker.release)
+				<-stopped
+				if ran := atomic.LoadInt64(&runs); ran != 0 {
+					t.Fatalf("round %d: %d tasks ran after the shutdown", round, ran)
+				}
+			}
+		})
+	}
+}
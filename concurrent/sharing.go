@@ -0,0 +1,138 @@
+package concurrent
+
+import (
+	"sync"
+)
+
+// Shared Context for Work Sharing
+type sharedContextSH struct {
+	queue        DEQueue // Tasks submitted to the executor, shared by all workers
+	signal       *idleSignal
+	idleStrategy IdleStrategy
+	tracer       *Tracer    // Records the scheduling events of the workers (nil if not traced)
+	lifecycle    *lifecycle // Shared by the service and the workers
+	wg           *sync.WaitGroup
+}
+
+// Work Sharing Sharer
+type sharer struct {
+	*submitter
+	workers []*workerSH
+	context *sharedContextSH
+}
+
+// Work Sharing Worker
+type workerSH struct {
+	id       int
+	context  *sharedContextSH
+	idler    *idler
+	stats    *workerStats
+	forkJoin *forkJoinWorker // Used by the fork-join tasks run by the worker
+}
+
+// Returns a new Work Sharing Worker
+func NewWorkerSH(id int, context *sharedContextSH) *workerSH {
+	worker := &workerSH{
+		id:      id,
+		context: context,
+		idler:   newIdler(context.idleStrategy, context.signal),
+		stats:   newWorkerStats(id, context.tracer),
+	}
+	worker.forkJoin = newForkJoinWorker(id, nil, nil, context.queue, context.signal, nil, worker.stats)
+	return worker
+}
+
+// Worker routine
+func (worker *workerSH) work() {
+	// Worker loops while tasks may still be submitted or the shared queue is not empty
+	// (the idle epoch is observed before checking for work so that no wakeup is missed)
+	for epoch := worker.idler.observe(); worker.context.lifecycle.open() || !worker.context.queue.IsEmpty(); epoch = worker.idler.observe() {
+		// Take the oldest task of the shared queue, the depth recorded is the one of the shared queue
+		worker.stats.observeQueue(worker.context.queue.Size())
+		workerTask := worker.context.queue.PopTop()
+		if workerTask != nil {
+			// Run the task
+			worker.stats.working()
+			worker.stats.runTask(workerTask, worker.forkJoin)
+			worker.idler.reset()
+			continue
+		}
+
+		// No work was found, idle before looking again
+		worker.stats.idling()
+		worker.idler.idle(epoch, worker.context.queue.IsEmpty)
+	}
+
+	// Worker is done
+	worker.stats.working()
+	worker.context.wg.Done()
+}
+
+// NewWorkSharingExecutor returns an ExecutorService that is implemented using a single queue shared by all workers.
+// It is the simplest parallel executor and serves as a baseline for the work-stealing and work-balancing executors.
+// @param capacity - The number of goroutines in the pool
+// @param options - Optional settings of the executor, only WithIdleStrategy, WithPriorityLevels,
+// WithTracer and WithMaxPending apply
+func NewWorkSharingExecutor(capacity int, options ...Option) ExecutorService {
+	// Apply the optional settings
+	config := newOptions(options)
+
+	// Create shared context
+	signal := newIdleSignal()
+	context := &sharedContextSH{
+		queue:        config.newInjectionQueue(),
+		signal:       signal,
+		idleStrategy: config.idleStrategy,
+		tracer:       config.tracer,
+		lifecycle:    newLifecycle(signal),
+		wg:           &sync.WaitGroup{},
+	}
+
+	// Create capacity workers
+	workers := []*workerSH{}
+	for i := 0; i < capacity; i++ {
+		workers = append(workers, NewWorkerSH(i, context))
+	}
+
+	// Spawn worker routines
+	for _, worker := range workers {
+		// Increment wait group (Decrement inside the work() method)
+		context.wg.Add(1)
+		go worker.work()
+	}
+	context.lifecycle.watch(context.wg)
+
+	// Create service
+	service := &sharer{
+		submitter: newSubmitter(config, context.lifecycle, context.queue, context.signal),
+		workers:   workers,
+		context:   context,
+	}
+
+	return service
+}
+
+// Stats returns a snapshot of the statistics of the workers
+func (service *sharer) Stats() Stats {
+	stats := Stats{Workers: []WorkerStats{}}
+	for _, worker := range service.workers {
+		stats.Workers = append(stats.Workers, worker.stats.snapshot())
+	}
+	return stats
+}
+
+// Shutdown the executor, it waits for all submitted tasks to run
+func (service *sharer) Shutdown() {
+	service.context.lifecycle.shutdown()
+
+	// Wait for all workers to finish
+	service.context.lifecycle.wait()
+}
+
+// ShutdownNow shuts down the executor without running the tasks that have not started yet
+func (service *sharer) ShutdownNow() []interface{} {
+	service.context.lifecycle.shutdown()
+
+	// Empty the shared queue so that the workers exit after their current task
+	return drainQueues(service.context.lifecycle, service.context.queue, nil)
+}
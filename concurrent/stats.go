@@ -0,0 +1,201 @@
+package concurrent
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerStats holds the statistics of a single worker of an executor
+type WorkerStats struct {
+	TasksRun      int64         // Number of tasks run by the worker
+	StealAttempts int64         // Number of times the worker tried to steal (work stealing only)
+	Steals        int64         // Number of steal attempts that stole at least one task
+	TasksStolen   int64         // Number of tasks stolen by the worker
+	BalanceChecks int64         // Number of times the worker checked whether to balance (work balancing only)
+	Balances      int64         // Number of balance operations that moved at least one task
+	TasksMoved    int64         // Number of tasks moved by the balance operations of the worker
+	Busy          time.Duration // Time spent running tasks
+	Idle          time.Duration // Time spent without finding any work
+	MaxQueueDepth int64         // Largest size of the local queue observed by the worker (of the shared queue for work sharing)
+}
+
+// Stats is a snapshot of the statistics of an executor
+type Stats struct {
+	Workers []WorkerStats // Statistics of each worker, indexed by worker id
+}
+
+// Total returns the statistics summed over all workers (the maximum for MaxQueueDepth)
+func (stats Stats) Total() WorkerStats {
+	total := WorkerStats{}
+	for _, worker := range stats.Workers {
+		total.TasksRun += worker.TasksRun
+		total.StealAttempts += worker.StealAttempts
+		total.Steals += worker.Steals
+		total.TasksStolen += worker.TasksStolen
+		total.BalanceChecks += worker.BalanceChecks
+		total.Balances += worker.Balances
+		total.TasksMoved += worker.TasksMoved
+		total.Busy += worker.Busy
+		total.Idle += worker.Idle
+		if worker.MaxQueueDepth > total.MaxQueueDepth {
+			total.MaxQueueDepth = worker.MaxQueueDepth
+		}
+	}
+	return total
+}
+
+// String formats the statistics as a table with one row per worker
+func (stats Stats) String() string {
+	builder := &strings.Builder{}
+	format := "%-8v %8v %8v %8v %8v %8v %8v %8v %10v %10v %8v\n"
+	fmt.Fprintf(builder, format, "worker", "run", "steal?", "steals", "stolen", "balance?", "balances", "moved", "busy", "idle", "depth")
+
+	row := func(name interface{}, worker WorkerStats) {
+		fmt.Fprintf(builder, format, name, worker.TasksRun, worker.StealAttempts, worker.Steals, worker.TasksStolen,
+			worker.BalanceChecks, worker.Balances, worker.TasksMoved,
+			worker.Busy.Round(time.Millisecond), worker.Idle.Round(time.Millisecond), worker.MaxQueueDepth)
+	}
+	for id, worker := range stats.Workers {
+		row(id, worker)
+	}
+	row("total", stats.Total())
+	return builder.String()
+}
+
+// workerStats collects the statistics of a worker. Counters are only written by the worker
+// itself but are read atomically since snapshots may be taken at any time.
+type workerStats struct {
+	tasksRun      int64
+	stealAttempts int64
+	steals        int64
+	tasksStolen   int64
+	balanceChecks int64
+	balances      int64
+	tasksMoved    int64
+	busy          int64 // Nanoseconds
+	idle          int64 // Nanoseconds
+	idleSince     int64 // Unix nanoseconds at which the current idle period started (0 if busy)
+	maxQueueDepth int64
+	id            int     // Id of the worker in the trace
+	tracer        *Tracer // Records the events of the worker (nil if the executor is not traced)
+}
+
+func newWorkerStats(id int, tracer *Tracer) *workerStats {
+	return &workerStats{
+		id:     id,
+		tracer: tracer,
+	}
+}
+
+// Run a task and record the time spent running it
+func (stats *workerStats) runTask(task Task, worker *forkJoinWorker) {
+	start := time.Now()
+	name := runTask(task, worker)
+	duration := time.Since(start)
+	atomic.AddInt64(&stats.busy, int64(duration))
+	atomic.AddInt64(&stats.tasksRun, 1)
+
+	if name != "" {
+		stats.tracer.span(stats.id, name, "task", start, duration)
+	}
+}
+
+// Run a task while joining another task, the time is already recorded for the joining task
+func (stats *workerStats) helpTask(task Task, worker *forkJoinWorker) {
+	start := time.Now()
+	name := runTask(task, worker)
+	atomic.AddInt64(&stats.tasksRun, 1)
+
+	if name != "" {
+		stats.tracer.span(stats.id, name, "task", start, time.Since(start))
+	}
+}
+
+// Returns the name of a task in the trace (empty if the worker is not traced)
+func (stats *workerStats) traceName(task interface{}) string {
+	if stats.tracer == nil {
+		return ""
+	}
+	return taskName(task)
+}
+
+// Record a steal attempt on the victim that stole the given number of tasks
+func (stats *workerStats) stealAttempt(victim, stolen int) {
+	atomic.AddInt64(&stats.stealAttempts, 1)
+	if stolen > 0 {
+		atomic.AddInt64(&stats.steals, 1)
+		atomic.AddInt64(&stats.tasksStolen, int64(stolen))
+
+		if stats.tracer != nil {
+			stats.tracer.instant(stats.id, "steal", map[string]interface{}{"victim": victim, "tasks": stolen})
+		}
+	}
+}
+
+// Record a balance check that moved the given number of tasks from one worker to another
+func (stats *workerStats) balanceCheck(from, to, moved int) {
+	atomic.AddInt64(&stats.balanceChecks, 1)
+	if moved > 0 {
+		atomic.AddInt64(&stats.balances, 1)
+		atomic.AddInt64(&stats.tasksMoved, int64(moved))
+
+		if stats.tracer != nil {
+			stats.tracer.instant(stats.id, "balance", map[string]interface{}{"from": from, "to": to, "tasks": moved})
+		}
+	}
+}
+
+// Record the current size of the local queue
+func (stats *workerStats) observeQueue(size int) {
+	if int64(size) > atomic.LoadInt64(&stats.maxQueueDepth) {
+		atomic.StoreInt64(&stats.maxQueueDepth, int64(size))
+	}
+}
+
+// The worker did not find any work, starts an idle period unless one has already started
+func (stats *workerStats) idling() {
+	if atomic.LoadInt64(&stats.idleSince) == 0 {
+		atomic.StoreInt64(&stats.idleSince, time.Now().UnixNano())
+	}
+}
+
+// The worker found work, ends the current idle period
+func (stats *workerStats) working() {
+	if since := atomic.SwapInt64(&stats.idleSince, 0); since != 0 {
+		idle := time.Now().UnixNano() - since
+		atomic.AddInt64(&stats.idle, idle)
+
+		if stats.tracer != nil {
+			stats.tracer.span(stats.id, "idle", "idle", time.Unix(0, since), time.Duration(idle))
+		}
+	}
+}
+
+// Returns whether the worker is currently idle
+func (stats *workerStats) isIdle() bool {
+	return atomic.LoadInt64(&stats.idleSince) != 0
+}
+
+// Returns a snapshot of the statistics
+func (stats *workerStats) snapshot() WorkerStats {
+	idle := atomic.LoadInt64(&stats.idle)
+	// Include the current idle period
+	if since := atomic.LoadInt64(&stats.idleSince); since != 0 {
+		idle += time.Now().UnixNano() - since
+	}
+
+	return WorkerStats{
+		TasksRun:      atomic.LoadInt64(&stats.tasksRun),
+		StealAttempts: atomic.LoadInt64(&stats.stealAttempts),
+		Steals:        atomic.LoadInt64(&stats.steals),
+		TasksStolen:   atomic.LoadInt64(&stats.tasksStolen),
+		BalanceChecks: atomic.LoadInt64(&stats.balanceChecks),
+		Balances:      atomic.LoadInt64(&stats.balances),
+		TasksMoved:    atomic.LoadInt64(&stats.tasksMoved),
+		Busy:          time.Duration(atomic.LoadInt64(&stats.busy)),
+		Idle:          time.Duration(idle),
+		MaxQueueDepth: atomic.LoadInt64(&stats.maxQueueDepth),
+	}
+}
@@ -0,0 +1,136 @@
+package concurrent
+
+import (
+	"testing"
+	"time"
+)
+
+// Runnable signalling once it runs
+type startingTask struct {
+	started chan struct{}
+}
+
+func (task *startingTask) Run() {
+	task.started <- struct{}{}
+}
+
+// ForkJoinTask forking its children and waiting until another worker has started one of them,
+// i.e. stole it or took it while balancing. Returns whether one was started in time.
+type handOffTask struct {
+	children int
+}
+
+func (task handOffTask) Compute(fj ForkJoin) interface{} {
+	started := make(chan struct{}, task.children)
+	children := []Future{}
+	for i := 0; i < task.children; i++ {
+		children = append(children, fj.Fork(&startingTask{started: started}))
+	}
+
+	handedOff := false
+	select {
+	case <-started:
+		handedOff = true
+	case <-time.After(5 * time.Second):
+	}
+	for _, child := range children {
+		fj.Join(child)
+	}
+	return handedOff
+}
+
+// The statistics add up to the tasks that were run and record how the workers shared them
+func TestStats(t *testing.T) {
+	forEachExecutorService(t, 2, func(t *testing.T, executor ExecutorService) {
+		stats := executor.(StatsExecutorService)
+
+		// The workers idle until the first task is submitted
+		idle := func() bool {
+			for _, worker := range stats.Stats().Workers {
+				if worker.Idle == 0 {
+					return false
+				}
+			}
+			return true
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for !idle() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		// The forked children can only be run by the other worker
+		if handedOff := executor.Submit(handOffTask{children: 2}).Get(); handedOff != true {
+			t.Fatal("no forked task was run by the other worker")
+		}
+		checkSquares(t, executor, 20)
+		executor.Shutdown()
+
+		snapshot := stats.Stats()
+		if len(snapshot.Workers) != 2 {
+			t.Fatalf("stats of %d workers, expected 2", len(snapshot.Workers))
+		}
+		for id, worker := range snapshot.Workers {
+			if worker.Idle <= 0 {
+				t.Fatalf("worker %d recorded no idle time", id)
+			}
+		}
+		total := snapshot.Total()
+		if total.TasksRun != 23 {
+			t.Fatalf("workers ran %d tasks, expected 23", total.TasksRun)
+		}
+		if total.Busy <= 0 {
+			t.Fatal("workers recorded no busy time")
+		}
+
+		// Only the counters of the way the executor shares its work move
+		switch executor.(type) {
+		case *stealer:
+			if total.Steals < 1 || total.TasksStolen < total.Steals || total.StealAttempts < total.Steals {
+				t.Fatalf("workers recorded %d steals of %d tasks in %d attempts", total.Steals, total.TasksStolen, total.StealAttempts)
+			}
+			if total.BalanceChecks != 0 {
+				t.Fatalf("work stealing workers recorded %d balance checks", total.BalanceChecks)
+			}
+		case *balancer:
+			if total.Balances < 1 || total.TasksMoved < total.Balances || total.BalanceChecks < total.Balances {
+				t.Fatalf("workers recorded %d balances of %d tasks in %d checks", total.Balances, total.TasksMoved, total.BalanceChecks)
+			}
+			if total.StealAttempts != 0 {
+				t.Fatalf("work balancing workers recorded %d steal attempts", total.StealAttempts)
+			}
+		default:
+			if total.StealAttempts != 0 || total.BalanceChecks != 0 {
+				t.Fatalf("work sharing workers recorded %d steal attempts and %d balance checks", total.StealAttempts, total.BalanceChecks)
+			}
+		}
+	})
+}
+
+// The workers record the largest number of tasks waiting in their queue
+func TestStatsQueueDepth(t *testing.T) {
+	executors := []struct {
+		name     string
+		executor ExecutorService
+	}{
+		{"ws", NewWorkStealingExecutor(1, 4)},
+		{"wb", NewWorkBalancingExecutor(1, 4, 2)},
+		{"wsh", NewWorkSharingExecutor(1)},
+	}
+	for _, implementation := range executors {
+		executor := implementation.executor
+		t.Run(implementation.name, func(t *testing.T) {
+			// The tasks wait while the only worker is busy, the worker grabs 4 at once
+			blocker := &blockingTask{release: make(chan struct{})}
+			executor.Submit(blocker)
+			for i := 0; i < 10; i++ {
+				executor.Submit(&squareTask{n: i})
+			}
+			close(blocker.release)
+			executor.Shutdown()
+
+			if depth := executor.(StatsExecutorService).Stats().Total().MaxQueueDepth; depth < 3 {
+				t.Fatalf("worker recorded a queue depth of %d, expected at least 3", depth)
+			}
+		})
+	}
+}
@@ -1,130 +1,126 @@
 package concurrent
 
-import (
-	"math/rand"
-	"sync"
-	"time"
-)
-
 // Shared Context for Work Stealing
 type sharedContextST struct {
-	capacity int
-	queues   []DEQueue
-	wg       *sync.WaitGroup
+	*poolContext
+	stealPolicy StealPolicy // How many tasks are taken from a victim in one steal
+	stealBatch  int         // Number of tasks stolen at once with StealBatch
 }
 
 // Work Stealing Stealer
 type stealer struct {
-	workers         []*workerST
-	done            bool
-	prevDistributee int
-	context         *sharedContextST
+	*workerPool
 }
 
 // Work Stealing Worker
 type workerST struct {
-	id            int
-	context       *sharedContextST
-	randGen       *rand.Rand
-	workRemaining bool
-	victimOptions []int
+	*poolWorker
+	context *sharedContextST
 }
 
-// Returns a new Work Stealing Stealer
+// Returns a new Work Stealing Worker
 func NewWorkerST(id int, context *sharedContextST) *workerST {
-	// Add the appropriate stealing options
-	victims := []int{}
-	for i := 0; i < context.capacity; i++ {
-		// Worker cant steal from itself
-		if i != id {
-			victims = append(victims, i)
-		}
-	}
-
 	return &workerST{
-		id:            id,
-		context:       context,
-		randGen:       rand.New(rand.NewSource(time.Now().UnixNano())),
-		workRemaining: true,
-		victimOptions: victims,
+		poolWorker: newPoolWorker(id, context.poolContext),
+		context:    context,
 	}
 }
 
-// Check if all queues are empty
-func (worker *workerST) isWorkPoolEmpty() bool {
-	// Check if all queues are empty
-	for _, queue := range worker.context.queues {
-		if !queue.IsEmpty() {
-			return false
-		}
-	}
-	return true
-}
-
-// Get a random victim
-func (worker *workerST) getVictim() int {
-	// Get random victim
-	victimIdx := worker.randGen.Intn(len(worker.victimOptions))
-	// Victim will not be the worker itself since we have removed it from the options
-	// Remove the victim from the options
-	victim := worker.victimOptions[victimIdx]
-	worker.victimOptions = append(worker.victimOptions[:victimIdx], worker.victimOptions[victimIdx+1:]...)
-	return victim
-}
-
 func stealingPolicy(smallQueue, largeQueue DEQueue) bool {
 	// Check if the size of the smaller queue is 0 and the larger queue has at least 1 element
 	return smallQueue.IsEmpty() && !largeQueue.IsEmpty()
 }
 
-// Steal a task from the victim
-func (worker *workerST) steal() {
+// StealPolicy decides how many tasks a thief takes from its victim in one steal
+type StealPolicy int
+
+const (
+	// StealOne steals a single task
+	StealOne StealPolicy = iota
+	// StealHalf steals half of the tasks in the victim's queue (rounded up)
+	StealHalf
+	// StealBatch steals a fixed number of tasks
+	StealBatch
+)
+
+// Number of tasks to steal from a victim holding size tasks
+func stealAmount(policy StealPolicy, batch, size int) int {
+	switch policy {
+	case StealHalf:
+		return (size + 1) / 2
+	case StealBatch:
+		return batch
+	default:
+		return 1
+	}
+}
+
+// Steal tasks from the victim, returns whether a task was stolen
+func (worker *workerST) steal() bool {
 	// Get the victim to balance with
 	victimIdx := worker.getVictim()
-	victim := worker.context.queues[victimIdx]
-	workerQueue := worker.context.queues[worker.id]
+	victim := worker.queues[victimIdx]
+	workerQueue := worker.queue
 
 	// Check if the queues need to be balanced
+	stolen := 0
 	if stealingPolicy(workerQueue, victim) {
-		// Get the task to move
-		job := victim.PopBottom()
-
-		// Add the task to the small queue
-		if job != nil {
-			workerQueue.PushBottom(job)
-			// Add all options back to the stealing options
-			for i := 0; i < worker.context.capacity; i++ {
-				if i != worker.id {
-					worker.victimOptions = append(worker.victimOptions, i)
-				}
-			}
-		}
+		// Move the tasks from the top of the victim's queue into the local queue
+		amount := stealAmount(worker.context.stealPolicy, worker.context.stealBatch, victim.Size())
+		stolen = workerQueue.grab(victim, amount)
 	}
+
+	// Let the victim selector know how the steal went
+	worker.victims.Report(victimIdx, stolen > 0)
+	worker.stats.stealAttempt(victimIdx, stolen)
+	return stolen > 0
 }
 
 // Worker routine
 func (worker *workerST) work() {
 	// Worker loops if work is remaining in its own queue or the overall work pool
-	for worker.workRemaining || !worker.isWorkPoolEmpty() {
+	// (the idle epoch is observed before checking for work so that no wakeup is missed)
+	for epoch := worker.idler.observe(); worker.running(); epoch = worker.idler.observe() {
+		worker.refresh()
+
 		// Finish all of your own tasks before stealing
-		workerTask := worker.context.queues[worker.id].PopTop()
+		found := false
+		worker.grabUrgent()
+		workerTask := worker.queue.PopBottom()
 		for workerTask != nil {
-			runnable, ok := workerTask.(Runnable)
-			if ok {
-				// Run the task
-				runnable.Run()
-			}
-			// Get the next task
-			workerTask = worker.context.queues[worker.id].PopTop()
+			// Run the task
+			worker.stats.working()
+			worker.stats.runTask(workerTask, worker.forkJoin)
+			found = true
+			// Get the next task (submitted tasks of a higher priority go first)
+			worker.grabUrgent()
+			workerTask = worker.queue.PopBottom()
 		}
 
-		if worker.context.capacity > 1 && len(worker.victimOptions) > 0 {
-			worker.steal()
+		// Grab a batch of submitted tasks before stealing
+		if worker.grab() > 0 {
+			worker.stats.observeQueue(worker.queue.Size())
+			worker.idler.reset()
+			continue
 		}
+
+		if worker.capacity > 1 && worker.steal() {
+			worker.stats.observeQueue(worker.queue.Size())
+			found = true
+		}
+
+		if found {
+			worker.idler.reset()
+			continue
+		}
+
+		// No work was found, idle before looking again
+		worker.stats.idling()
+		worker.idler.idle(epoch, worker.isWorkPoolEmpty)
 	}
 
-	// Worker is done
-	worker.context.wg.Done()
+	// Hand the remaining tasks over if the worker was retired
+	worker.exit()
 }
 
 // NewWorkStealingExecutor returns an ExecutorService that is implemented using the work-stealing algorithm.
@@ -134,75 +130,24 @@ func (worker *workerST) work() {
 // this means that a goroutine can grab 10 items from the executor all at
 // once to place into their local queue before grabbing more items. It's
 // not required that you use this parameter in your implementation.
-func NewWorkStealingExecutor(capacity, threshold int) ExecutorService {
-	// Create capacity queues
-	queues := []DEQueue{}
-	for i := 0; i < capacity; i++ {
-		queues = append(queues, NewUnBoundedDEQueue())
-	}
+// @param options - Optional settings of the executor (e.g. WithDEQueue)
+func NewWorkStealingExecutor(capacity, threshold int, options ...Option) ExecutorService {
+	// Apply the optional settings
+	config := newOptions(options)
 
 	// Create shared context
 	context := &sharedContextST{
-		capacity: capacity,
-		queues:   queues,
-		wg:       &sync.WaitGroup{},
+		poolContext: newPoolContext(capacity, threshold, config),
+		stealPolicy: config.stealPolicy,
+		stealBatch:  config.stealBatch,
 	}
 
-	// Create capacity workers
-	workers := []*workerST{}
-	for i := 0; i < capacity; i++ {
-		workers = append(workers, NewWorkerST(i, context))
-	}
-
-	// Spawn worker routines
-	for _, worker := range workers {
-		// Increment wait group (Decrement inside the Run() method)
-		context.wg.Add(1)
-		go worker.work()
-	}
-
-	// Create service
+	// Create service, it creates and spawns capacity workers
 	service := &stealer{
-		workers:         workers,
-		done:            false,
-		prevDistributee: capacity - 1,
-		context:         context,
+		workerPool: newWorkerPool(capacity, context.poolContext, config, func(id int) (*poolWorker, func()) {
+			worker := NewWorkerST(id, context)
+			return worker.poolWorker, worker.work
+		}),
 	}
-
 	return service
 }
-
-// Get index of the next worker to distribute work to
-func (service *stealer) nextDistributee() int {
-	// Get next distributee and update prevDistributee
-	service.prevDistributee = (service.prevDistributee + 1) % service.context.capacity
-	return service.prevDistributee
-}
-
-// Submit a task to the executor
-func (service *stealer) Submit(task interface{}) Future {
-	// Check if service is done
-	if service.done {
-		return nil
-	}
-	// Get next distributee
-	distributee := service.nextDistributee()
-	// Add task to distributee's queue
-	service.context.queues[distributee].PushBottom(task)
-	// Type assertion
-	return task.(Future)
-}
-
-// Shutdown the executor
-func (service *stealer) Shutdown() {
-	// Indicate no more work is remaining
-	for _, worker := range service.workers {
-		worker.workRemaining = false
-	}
-
-	// Indicate the service is done
-	service.done = true
-
-	// Wait for all workers to finish
-	service.context.wg.Wait()
-}
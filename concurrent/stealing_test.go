@@ -0,0 +1,79 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestStealAmount(t *testing.T) {
+	tests := []struct {
+		policy StealPolicy
+		batch  int
+		size   int
+		amount int
+	}{
+		{StealOne, 4, 10, 1},
+		{StealOne, 4, 1, 1},
+		{StealHalf, 4, 1, 1},
+		{StealHalf, 4, 7, 4},
+		{StealHalf, 4, 8, 4},
+		{StealHalf, 4, 0, 0},
+		{StealBatch, 4, 10, 4},
+		{StealBatch, 1, 10, 1},
+	}
+	for _, test := range tests {
+		if amount := stealAmount(test.policy, test.batch, test.size); amount != test.amount {
+			t.Errorf("policy %d (batch %d) steals %d of %d tasks, expected %d", test.policy, test.batch, amount, test.size, test.amount)
+		}
+	}
+}
+
+// A thief takes the amount of its policy from the top of the victim's queue, at most the tasks it holds
+func TestSteal(t *testing.T) {
+	tests := []struct {
+		option Option
+		queued int
+		stolen int
+	}{
+		{WithStealPolicy(StealOne, 0), 7, 1},
+		{WithStealPolicy(StealHalf, 0), 7, 4},
+		{WithStealPolicy(StealBatch, 3), 7, 3},
+		{WithStealPolicy(StealBatch, 3), 2, 2},
+	}
+	for i, test := range tests {
+		test := test
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			config := newOptions([]Option{test.option})
+			context := &sharedContextST{
+				poolContext: newPoolContext(2, 1, config),
+				stealPolicy: config.stealPolicy,
+				stealBatch:  config.stealBatch,
+			}
+			thief := NewWorkerST(0, context)
+			victim := context.queues.load()[1]
+			for n := 0; n < test.queued; n++ {
+				victim.PushBottom(newQueuedJob(n))
+			}
+
+			if !thief.steal() {
+				t.Fatal("nothing was stolen")
+			}
+			if size := thief.queue.Size(); size != test.stolen {
+				t.Fatalf("stole %d of %d tasks, expected %d", size, test.queued, test.stolen)
+			}
+			// The oldest tasks are stolen
+			if job := thief.queue.PopTop().(*future); job.task.(*squareTask).n != 0 {
+				t.Fatalf("stole task %d first, expected the oldest one", job.task.(*squareTask).n)
+			}
+			if stats := thief.stats.snapshot(); stats.Steals != 1 || stats.TasksStolen != int64(test.stolen) {
+				t.Fatalf("recorded %d steals of %d tasks", stats.Steals, stats.TasksStolen)
+			}
+		})
+	}
+}
+
+// Returns a job queued in an executor
+func newQueuedJob(n int) *future {
+	return newFuture(context.Background(), &squareTask{n: n})
+}
@@ -0,0 +1,104 @@
+package concurrent
+
+import (
+	"context"
+	"time"
+)
+
+// submitter implements the submission and lifecycle methods shared by the executors, the
+// accepted tasks are pushed to the queue the workers take the submitted tasks from
+type submitter struct {
+	lifecycle      *lifecycle // Shared with the workers
+	bound          *bound     // Limits the number of pending tasks (nil if unbounded)
+	priorityLevels int        // Number of priority levels of the tasks
+	queue          DEQueue    // Receives the submitted tasks
+	signal         *idleSignal
+}
+
+// Returns a new submitter pushing the tasks to the queue, bounded by the settings of the executor
+func newSubmitter(config *options, l *lifecycle, queue DEQueue, signal *idleSignal) *submitter {
+	return &submitter{
+		lifecycle:      l,
+		bound:          newBound(config.maxPending),
+		priorityLevels: config.priorityLevels,
+		queue:          queue,
+		signal:         signal,
+	}
+}
+
+// Submit a task to the executor
+func (service *submitter) Submit(task interface{}) Future {
+	return service.SubmitContext(context.Background(), task)
+}
+
+// Submit a task bound to a context to the executor
+func (service *submitter) SubmitContext(ctx context.Context, task interface{}) Future {
+	return service.SubmitPriority(ctx, task, DefaultPriority)
+}
+
+// Submit a task bound to a context with a priority to the executor, it is safe to call from any goroutine
+func (service *submitter) SubmitPriority(ctx context.Context, task interface{}, priority Priority) Future {
+	// Wait for the number of pending tasks to drop below the bound
+	if err := service.bound.acquire(ctx, service.lifecycle); err != nil {
+		return failedFuture(task, err)
+	}
+	return service.submit(ctx, task, priority)
+}
+
+// Submit a task unless the number of pending tasks has reached the bound
+func (service *submitter) TrySubmit(task interface{}) (Future, bool) {
+	if !service.bound.tryAcquire() {
+		return nil, false
+	}
+	return service.submit(context.Background(), task, DefaultPriority), true
+}
+
+// Submit a task once it has acquired a slot of the bound
+func (service *submitter) submit(ctx context.Context, task interface{}, priority Priority) Future {
+	// Reject the task if the service is shut down
+	if !service.lifecycle.beginSubmit() {
+		service.bound.release()
+		return rejectedFuture(task)
+	}
+	defer service.lifecycle.release()
+
+	// Wrap the task in a future owned by the executor, its slot is given back once it is done
+	job := newFuture(ctx, task)
+	job.priority = clampPriority(priority, service.priorityLevels)
+	service.bound.track(job)
+
+	// Add task to the queue and wake up the idle workers
+	service.push(job)
+	return job
+}
+
+// Submit a task that is only queued once its dependencies have completed
+func (service *submitter) SubmitAfter(task interface{}, deps ...Future) Future {
+	return submitAfter(service.lifecycle, service.push, newFuture(context.Background(), task), deps)
+}
+
+// Submit the tasks of a graph bound to a context, each after its dependencies
+func (service *submitter) SubmitGraph(ctx context.Context, graph *TaskGraph) ([]Future, error) {
+	return submitGraph(service.lifecycle, service.push, ctx, graph)
+}
+
+// Add a job to the queue and wake up the idle workers
+func (service *submitter) push(job *future) {
+	service.queue.PushBottom(job)
+	service.signal.notify()
+}
+
+// AwaitTermination waits at most timeout for all workers to finish
+func (service *submitter) AwaitTermination(timeout time.Duration) bool {
+	return service.lifecycle.await(timeout)
+}
+
+// IsShutdown returns whether the executor was shut down
+func (service *submitter) IsShutdown() bool {
+	return service.lifecycle.isShutdown()
+}
+
+// IsTerminated returns whether all workers finished after a shutdown
+func (service *submitter) IsTerminated() bool {
+	return service.lifecycle.isTerminated()
+}
@@ -0,0 +1,122 @@
+package concurrent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracer records the scheduling events of the executors it is given to (see WithTracer): the
+// tasks run by each worker, steals, balance operations and idle periods. The events can be
+// written in the Chrome trace-event format, which can be opened in chrome://tracing or Perfetto.
+// A Tracer is safe to use from multiple goroutines.
+type Tracer struct {
+	mu      sync.Mutex
+	start   time.Time    // Events are timed relative to the creation of the tracer
+	events  []traceEvent // Recorded events in the order they were recorded
+	workers map[int]bool // Ids of the workers that recorded an event
+}
+
+// Event of the Chrome trace-event format
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	Ts    float64                `json:"ts"`            // Microseconds
+	Dur   float64                `json:"dur,omitempty"` // Microseconds (complete events only)
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"` // Scope of instant events
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// NewTracer returns a Tracer without any events
+func NewTracer() *Tracer {
+	return &Tracer{
+		start:   time.Now(),
+		events:  []traceEvent{},
+		workers: map[int]bool{},
+	}
+}
+
+// Microseconds elapsed between the creation of the tracer and t
+func (tracer *Tracer) timestamp(t time.Time) float64 {
+	return float64(t.Sub(tracer.start).Nanoseconds()) / float64(time.Microsecond)
+}
+
+// Record an event of a worker
+func (tracer *Tracer) record(worker int, event traceEvent) {
+	event.Pid = 1
+	event.Tid = worker
+
+	tracer.mu.Lock()
+	tracer.events = append(tracer.events, event)
+	tracer.workers[worker] = true
+	tracer.mu.Unlock()
+}
+
+// Record a period of time spent by a worker
+func (tracer *Tracer) span(worker int, name, category string, start time.Time, duration time.Duration) {
+	tracer.record(worker, traceEvent{
+		Name:  name,
+		Cat:   category,
+		Phase: "X",
+		Ts:    tracer.timestamp(start),
+		Dur:   float64(duration.Nanoseconds()) / float64(time.Microsecond),
+	})
+}
+
+// Record an instant event of a worker
+func (tracer *Tracer) instant(worker int, name string, args map[string]interface{}) {
+	tracer.record(worker, traceEvent{
+		Name:  name,
+		Cat:   name,
+		Phase: "i",
+		Ts:    tracer.timestamp(time.Now()),
+		Scope: "t",
+		Args:  args,
+	})
+}
+
+// WriteJSON writes the recorded events as a Chrome trace-event JSON object. It should be called once
+// the executors are shut down, events recorded in the meantime may be missing.
+func (tracer *Tracer) WriteJSON(w io.Writer) error {
+	tracer.mu.Lock()
+	events := append([]traceEvent{}, tracer.events...)
+	workers := []int{}
+	for worker := range tracer.workers {
+		workers = append(workers, worker)
+	}
+	tracer.mu.Unlock()
+
+	// Name the thread of each worker
+	sort.Ints(workers)
+	for _, worker := range workers {
+		events = append(events, traceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Pid:   1,
+			Tid:   worker,
+			Args:  map[string]interface{}{"name": fmt.Sprintf("worker %d", worker)},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents     []traceEvent `json:"traceEvents"`
+		DisplayTimeUnit string       `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     events,
+		DisplayTimeUnit: "ms",
+	})
+}
+
+// Returns the name of a task in the trace, tasks can name themselves by implementing fmt.Stringer
+func taskName(task interface{}) string {
+	if stringer, ok := task.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", task)
+}
@@ -0,0 +1,135 @@
+package concurrent
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// Event of a trace written by WriteJSON
+type decodedEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat"`
+	Phase string                 `json:"ph"`
+	Dur   float64                `json:"dur"`
+	Tid   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args"`
+}
+
+// Writes the events of the tracer and decodes them back
+func decodeTrace(t *testing.T, tracer *Tracer) []decodedEvent {
+	buffer := &bytes.Buffer{}
+	if err := tracer.WriteJSON(buffer); err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents     []decodedEvent `json:"traceEvents"`
+		DisplayTimeUnit string         `json:"displayTimeUnit"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &trace); err != nil {
+		t.Fatal(err)
+	}
+	if trace.DisplayTimeUnit != "ms" {
+		t.Fatalf("display time unit %q", trace.DisplayTimeUnit)
+	}
+	return trace.TraceEvents
+}
+
+// Runnable naming itself in the traces
+type namedTask struct {
+	name string
+}
+
+func (task *namedTask) Run() {}
+
+func (task *namedTask) String() string {
+	return task.name
+}
+
+func TestTracerEvents(t *testing.T) {
+	tracer := NewTracer()
+	thief := newWorkerStats(2, tracer)
+	thief.stealAttempt(1, 3)
+	thief.stealAttempt(1, 0)
+	balancer := newWorkerStats(0, tracer)
+	balancer.balanceCheck(0, 2, 4)
+	balancer.balanceCheck(0, 2, 0)
+	balancer.idling()
+	balancer.working()
+
+	// Only the successful attempts are recorded, along with a name for each worker
+	events := decodeTrace(t, tracer)
+	expected := []string{"steal/i@2", "balance/i@0", "idle/X@0", "thread_name/M@0", "thread_name/M@2"}
+	if len(events) != len(expected) {
+		t.Fatalf("%d events recorded: %v", len(events), events)
+	}
+	for i, event := range events {
+		if actual := fmt.Sprintf("%s/%s@%d", event.Name, event.Phase, event.Tid); actual != expected[i] {
+			t.Fatalf("event %d is %s, expected %s", i, actual, expected[i])
+		}
+	}
+	if events[0].Args["victim"] != 1.0 || events[0].Args["tasks"] != 3.0 {
+		t.Fatalf("steal event has args %v", events[0].Args)
+	}
+	if events[1].Args["from"] != 0.0 || events[1].Args["to"] != 2.0 || events[1].Args["tasks"] != 4.0 {
+		t.Fatalf("balance event has args %v", events[1].Args)
+	}
+	if events[3].Args["name"] != "worker 0" {
+		t.Fatalf("worker 0 is named %v", events[3].Args["name"])
+	}
+}
+
+// Every task run by a traced executor is recorded once under its name, on one of the workers
+func TestTraceExecutors(t *testing.T) {
+	executors := []struct {
+		name        string
+		newExecutor func(tracer *Tracer) ExecutorService
+	}{
+		{"ws", func(tracer *Tracer) ExecutorService { return NewWorkStealingExecutor(4, 1, WithTracer(tracer)) }},
+		{"wb", func(tracer *Tracer) ExecutorService { return NewWorkBalancingExecutor(4, 1, 1, WithTracer(tracer)) }},
+		{"wsh", func(tracer *Tracer) ExecutorService { return NewWorkSharingExecutor(4, WithTracer(tracer)) }},
+	}
+	for _, implementation := range executors {
+		implementation := implementation
+		t.Run(implementation.name, func(t *testing.T) {
+			tracer := NewTracer()
+			executor := implementation.newExecutor(tracer)
+			for i := 0; i < 100; i++ {
+				executor.Submit(&namedTask{name: fmt.Sprintf("task %d", i)})
+			}
+			executor.Submit(fibonacciTask(10))
+			executor.Shutdown()
+
+			runs := map[string]int{}
+			named := map[int]bool{}
+			recorded := map[int]bool{}
+			for _, event := range decodeTrace(t, tracer) {
+				if event.Tid < 0 || event.Tid >= 4 {
+					t.Fatalf("event %v recorded by worker %d", event, event.Tid)
+				}
+				switch {
+				case event.Phase == "M":
+					named[event.Tid] = true
+				case event.Cat == "task":
+					runs[event.Name]++
+					recorded[event.Tid] = true
+				}
+			}
+			for i := 0; i < 100; i++ {
+				if name := fmt.Sprintf("task %d", i); runs[name] != 1 {
+					t.Fatalf("%s was recorded %d times", name, runs[name])
+				}
+			}
+			// fib(10) forks 88 subtasks besides itself
+			if runs["concurrent.fibonacciTask"] != 89 {
+				t.Fatalf("fib(10) recorded %d tasks, expected 89", runs["concurrent.fibonacciTask"])
+			}
+			for worker := range recorded {
+				if !named[worker] {
+					t.Fatalf("worker %d recorded tasks but was not named", worker)
+				}
+			}
+		})
+	}
+}
@@ -67,7 +67,7 @@ func (q *UnBoundedDEQueue) PushBottom(task Task) {
 	node := newNode(task)
 
 	// Increase the size of the queue
-	q.size += 1
+	atomic.AddInt64(&q.size, 1)
 
 	// Check if the queue is empty
 	if q.head == nil {
@@ -96,7 +96,7 @@ func (q *UnBoundedDEQueue) PopBottom() Task {
 	}
 
 	// Decrease the size of the queue
-	q.size -= 1
+	atomic.AddInt64(&q.size, -1)
 
 	// Check if the queue has only one element
 	if q.head == q.tail {
@@ -131,7 +131,7 @@ func (q *UnBoundedDEQueue) PopTop() Task {
 	}
 
 	// Decrease the size of the queue
-	q.size -= 1
+	atomic.AddInt64(&q.size, -1)
 
 	// Check if the queue has only one element
 	if q.head == q.tail {
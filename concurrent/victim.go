@@ -0,0 +1,168 @@
+package concurrent
+
+import (
+	"math/rand"
+)
+
+// VictimSelector chooses the victims of a single worker, i.e. the workers it steals from or
+// balances with. Every worker owns its own selector so implementations need no synchronization.
+type VictimSelector interface {
+	// Next returns the index of the next victim, size returns the current size of a worker's queue
+	Next(size func(victim int) int) int
+	// Report tells the selector whether taking work from the victim was successful
+	Report(victim int, success bool)
+}
+
+// NewVictimSelector creates the VictimSelector of the worker with the given id in a pool of capacity workers
+type NewVictimSelector func(id, capacity int, randGen *rand.Rand) VictimSelector
+
+// Returns the indices of all workers except the worker with the given id
+func otherWorkers(id, capacity int) []int {
+	others := []int{}
+	for i := 0; i < capacity; i++ {
+		// Worker cant be its own victim
+		if i != id {
+			others = append(others, i)
+		}
+	}
+	return others
+}
+
+// Random victim selection without replacement, every other worker is tried once (in random order)
+// before a worker is tried again. The options are refilled after a successful attempt.
+type randomSelector struct {
+	id       int
+	capacity int
+	randGen  *rand.Rand
+	options  []int
+}
+
+// NewRandomVictimSelector returns a selector picking victims at random
+func NewRandomVictimSelector(id, capacity int, randGen *rand.Rand) VictimSelector {
+	return &randomSelector{
+		id:       id,
+		capacity: capacity,
+		randGen:  randGen,
+		options:  otherWorkers(id, capacity),
+	}
+}
+
+func (s *randomSelector) Next(size func(victim int) int) int {
+	// Start a new round once every victim has been tried
+	if len(s.options) == 0 {
+		s.options = otherWorkers(s.id, s.capacity)
+	}
+
+	// Remove a random victim from the options
+	victimIdx := s.randGen.Intn(len(s.options))
+	victim := s.options[victimIdx]
+	s.options = append(s.options[:victimIdx], s.options[victimIdx+1:]...)
+	return victim
+}
+
+func (s *randomSelector) Report(victim int, success bool) {
+	// Add all options back after a successful attempt
+	if success {
+		s.options = otherWorkers(s.id, s.capacity)
+	}
+}
+
+// Round-robin victim selection starting from the next worker
+type roundRobinSelector struct {
+	id       int
+	capacity int
+	previous int
+}
+
+// NewRoundRobinVictimSelector returns a selector cycling through the other workers in order
+func NewRoundRobinVictimSelector(id, capacity int, randGen *rand.Rand) VictimSelector {
+	return &roundRobinSelector{
+		id:       id,
+		capacity: capacity,
+		previous: id,
+	}
+}
+
+func (s *roundRobinSelector) Next(size func(victim int) int) int {
+	// Get the next worker and skip the worker itself
+	s.previous = (s.previous + 1) % s.capacity
+	if s.previous == s.id {
+		s.previous = (s.previous + 1) % s.capacity
+	}
+	return s.previous
+}
+
+func (s *roundRobinSelector) Report(victim int, success bool) {}
+
+// Power of two choices, samples two distinct victims at random and picks the one with the longer queue
+type powerOfTwoSelector struct {
+	id       int
+	capacity int
+	randGen  *rand.Rand
+}
+
+// NewPowerOfTwoVictimSelector returns a selector picking the longer queue of two random victims
+func NewPowerOfTwoVictimSelector(id, capacity int, randGen *rand.Rand) VictimSelector {
+	return &powerOfTwoSelector{
+		id:       id,
+		capacity: capacity,
+		randGen:  randGen,
+	}
+}
+
+// Get a random victim other than the worker and the excluded worker
+func (s *powerOfTwoSelector) sample(excluded int) int {
+	victim := s.randGen.Intn(s.capacity)
+	for victim == s.id || victim == excluded {
+		victim = s.randGen.Intn(s.capacity)
+	}
+	return victim
+}
+
+func (s *powerOfTwoSelector) Next(size func(victim int) int) int {
+	first := s.sample(s.id)
+
+	// There is no second choice with only one other worker
+	if s.capacity < 3 {
+		return first
+	}
+
+	second := s.sample(first)
+	if size(second) > size(first) {
+		return second
+	}
+	return first
+}
+
+func (s *powerOfTwoSelector) Report(victim int, success bool) {}
+
+// Keeps going back to the last victim that work was successfully taken from and falls back
+// to random selection once it fails
+type lastVictimSelector struct {
+	last     int
+	fallback VictimSelector
+}
+
+// NewLastVictimSelector returns a selector that sticks to the last successful victim
+func NewLastVictimSelector(id, capacity int, randGen *rand.Rand) VictimSelector {
+	return &lastVictimSelector{
+		last:     -1,
+		fallback: NewRandomVictimSelector(id, capacity, randGen),
+	}
+}
+
+func (s *lastVictimSelector) Next(size func(victim int) int) int {
+	if s.last >= 0 {
+		return s.last
+	}
+	return s.fallback.Next(size)
+}
+
+func (s *lastVictimSelector) Report(victim int, success bool) {
+	if success {
+		s.last = victim
+	} else if victim == s.last {
+		s.last = -1
+	}
+	s.fallback.Report(victim, success)
+}
@@ -0,0 +1,93 @@
+package concurrent
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+var victimSelectors = []struct {
+	name              string
+	newVictimSelector NewVictimSelector
+}{
+	{"random", NewRandomVictimSelector},
+	{"round-robin", NewRoundRobinVictimSelector},
+	{"power-of-two", NewPowerOfTwoVictimSelector},
+	{"last-victim", NewLastVictimSelector},
+}
+
+// Every selector only picks other workers and eventually picks each of them
+func TestVictimSelectors(t *testing.T) {
+	for _, selector := range victimSelectors {
+		for _, capacity := range []int{2, 3, 5} {
+			selector, capacity := selector, capacity
+			t.Run(fmt.Sprintf("%s/capacity=%d", selector.name, capacity), func(t *testing.T) {
+				id := capacity - 1
+				victims := selector.newVictimSelector(id, capacity, rand.New(rand.NewSource(1)))
+				size := func(victim int) int { return 1 }
+
+				picked := map[int]bool{}
+				for i := 0; i < 100*capacity; i++ {
+					victim := victims.Next(size)
+					if victim < 0 || victim >= capacity || victim == id {
+						t.Fatalf("worker %d picked victim %d out of %d workers", id, victim, capacity)
+					}
+					picked[victim] = true
+					victims.Report(victim, false)
+				}
+				if len(picked) != capacity-1 {
+					t.Fatalf("picked %v, expected every other worker", picked)
+				}
+			})
+		}
+	}
+}
+
+// The last victim selector sticks to a successful victim until stealing from it fails
+func TestLastVictimSelector(t *testing.T) {
+	victims := NewLastVictimSelector(0, 4, rand.New(rand.NewSource(1)))
+	size := func(victim int) int { return 1 }
+
+	victim := victims.Next(size)
+	victims.Report(victim, true)
+	for i := 0; i < 10; i++ {
+		if next := victims.Next(size); next != victim {
+			t.Fatalf("picked %d after a successful attempt on %d", next, victim)
+		}
+		victims.Report(victim, true)
+	}
+	victims.Report(victim, false)
+	if next := victims.Next(size); next == victim {
+		t.Fatalf("picked %d again after a failed attempt", victim)
+	}
+}
+
+// The power of two selector never picks the shortest queue when it has two other workers to choose from
+func TestPowerOfTwoVictimSelector(t *testing.T) {
+	victims := NewPowerOfTwoVictimSelector(0, 3, rand.New(rand.NewSource(1)))
+	size := func(victim int) int { return victim }
+	for i := 0; i < 100; i++ {
+		if victim := victims.Next(size); victim != 2 {
+			t.Fatalf("picked %d with a queue of %d tasks over 2 with a queue of 2 tasks", victim, size(victim))
+		}
+	}
+}
+
+func TestVictimSelectorExecutors(t *testing.T) {
+	for _, selector := range victimSelectors {
+		for _, capacity := range []int{1, 2, 3, 5} {
+			selector, capacity := selector, capacity
+			t.Run(fmt.Sprintf("%s/capacity=%d", selector.name, capacity), func(t *testing.T) {
+				executors := []ExecutorService{
+					NewWorkStealingExecutor(capacity, 2, WithVictimSelector(selector.newVictimSelector),
+						WithStealPolicy(StealHalf, 0), WithDEQueue(NewChaseLevDEQueue)),
+					NewWorkBalancingExecutor(capacity, 3, 1, WithVictimSelector(selector.newVictimSelector)),
+				}
+				for _, executor := range executors {
+					checkSquares(t, executor, 500)
+					executor.Shutdown()
+				}
+			})
+		}
+	}
+}
@@ -1,57 +1,148 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"proj3/concurrent"
 	"proj3/scheduler"
 	"strconv"
 	"time"
 )
 
-const usage = "Usage: editor data_dir mode [number of threads]\n" +
+const usage = "Usage: editor [options] data_dir mode [number of threads]\n" +
 	"data_dir = The data directory to use to load the images.\n" +
-	"[mode]     = (ws) run the work stealing mode, (wb) run the work balancing mode\n" +
+	"[mode]     = (ws) run the work stealing mode, (wb) run the work balancing mode, (wsh) run the work sharing mode\n" +
 	"[number of threads] = Runs the parallel version of the program with the specified number of threads.\n" +
-	"[threshold] = The threshold for the work stealing mode."
+	"[threshold] = The threshold for the work stealing mode.\n" +
+	"[options]:\n" +
+	"-steal = The amount of tasks stolen at once in the work stealing mode: one (default), half or a fixed number.\n" +
+	"-victim = How victims are chosen in the parallel modes: random (default), roundrobin, p2c (longer of two random queues) or last (last successful victim).\n" +
+	"-trigger = When to balance in the work balancing mode: random (default), empty (local queue is empty) or every fixed number of iterations.\n" +
+	"-pair = Who to balance with in the work balancing mode: victim (default, chosen by -victim) or imbalance (the most imbalanced queue).\n" +
+	"-move = How many tasks to move in the work balancing mode: threshold (default, until the difference is below the threshold), equal or a fixed number.\n" +
+	"-pending = The maximum number of images loaded but not processed yet in the parallel modes, which bounds the memory used (twice the number of threads by default, -1 for no bound).\n" +
+	"-stripes = Split every image into stripes of at most the given number of rows that the workers process in parallel in the parallel modes, which helps with fewer images than threads (every image is a single task by default).\n" +
+	"-seed = The seed of the random decisions in the parallel modes (e.g. choosing victims), any seed (including 0) can be given, a seed based on the time is used by default. The seed is printed to stderr to reproduce the run.\n" +
+	"-stats = Print the statistics of the workers (tasks run, steals, balancing, busy/idle time, queue depth) to stderr in the parallel modes.\n" +
+	"-trace = Write the scheduling events of the workers (tasks run, steals, balancing, idle periods) in the parallel modes to the given file as a Chrome trace (open it in chrome://tracing or Perfetto)."
 
 func main() {
+	// Parse the options given before the positional arguments
+	flag.Usage = func() { fmt.Println(usage) }
+	steal := flag.String("steal", "one", "")
+	victim := flag.String("victim", "random", "")
+	trigger := flag.String("trigger", "random", "")
+	pair := flag.String("pair", "victim", "")
+	move := flag.String("move", "threshold", "")
+	pending := flag.Int("pending", 0, "")
+	stripes := flag.Int("stripes", 0, "")
+	seed := flag.Int64("seed", 0, "")
+	stats := flag.Bool("stats", false, "")
+	trace := flag.String("trace", "", "")
+	flag.Parse()
+	args := flag.Args()
+
 	// Check for correct number of arguments
-	if len(os.Args) < 2 {
+	if len(args) < 1 {
 		fmt.Println(usage)
 		return
 	}
 
 	// Initialize the config
-	config := scheduler.Config{DataDirs: "", Mode: "", ThreadCount: 0, Threshold: 0}
-	config.DataDirs = os.Args[1]
+	config := scheduler.Config{DataDirs: "", Mode: "", ThreadCount: 0, Threshold: 0, Steal: *steal, Victim: *victim,
+		BalanceTrigger: *trigger, BalancePair: *pair, BalanceAmount: *move, MaxPending: *pending, StripeRows: *stripes}
+	config.DataDirs = args[0]
+
+	// Only use the seed if it was given, so that any seed (including 0) can be replayed
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			config.Seed = seed
+		}
+	})
+
+	// Record the scheduling events on request
+	if *trace != "" {
+		config.Tracer = concurrent.NewTracer()
+	}
 
 	// Check for correct number of arguments
-	if len(os.Args) > 5 {
+	if len(args) > 4 {
 		fmt.Println(usage)
 		return
 	}
 
-	if len(os.Args) == 5 {
+	if len(args) == 4 {
 		// Work balancing mode
-		config.Mode = os.Args[2]
-		threads, _ := strconv.Atoi(os.Args[3])
-		threshold, _ := strconv.Atoi(os.Args[4])
+		config.Mode = args[1]
+		threads, _ := strconv.Atoi(args[2])
+		threshold, _ := strconv.Atoi(args[3])
 		config.ThreadCount = threads
 		config.Threshold = threshold
-	} else if len(os.Args) == 4 {
-		// Work stealing mode
-		config.Mode = os.Args[2]
-		threads, _ := strconv.Atoi(os.Args[3])
+	} else if len(args) == 3 {
+		// Work stealing or work sharing mode
+		config.Mode = args[1]
+		threads, _ := strconv.Atoi(args[2])
 		config.ThreadCount = threads
 	} else {
 		// Sequential mode
 		config.Mode = "s"
 	}
 
+	// Stop scheduling new images when the user presses Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	// Time the image editor for all tasks in data/effects.txt
 	start := time.Now()
-	scheduler.Schedule(config)
+	result := scheduler.ScheduleContext(ctx, config)
 	end := time.Since(start).Seconds()
+
+	// Log the seed so that the run can be reproduced
+	fmt.Fprintf(os.Stderr, "Seed: %d\n", result.Seed)
+
+	// Write the trace, even for an interrupted run
+	if config.Tracer != nil {
+		if err := writeTrace(*trace, config.Tracer); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write the trace: %v\n", err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Interrupted after %.2f seconds\n", end)
+		stop()
+		os.Exit(1)
+	}
 	fmt.Printf("%.2f\n", end)
 
+	// Print the statistics of the executor on request
+	if *stats && result.Stats != nil {
+		fmt.Fprint(os.Stderr, result.Stats)
+	}
+
+	// Report the images that could not be processed
+	if len(result.Failures) > 0 {
+		fmt.Fprintf(os.Stderr, "Failed to process %d image(s):\n", len(result.Failures))
+		for _, failure := range result.Failures {
+			fmt.Fprintln(os.Stderr, failure)
+		}
+		stop()
+		os.Exit(1)
+	}
+
+}
+
+// Write the events recorded by the tracer to the given file
+func writeTrace(path string, tracer *concurrent.Tracer) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tracer.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
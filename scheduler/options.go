@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"proj3/concurrent"
+	"proj3/png"
+	"proj3/task"
+	"strconv"
+	"time"
+)
+
+// Get the steal policy of the work stealing executor from the Steal field of the configuration value
+func stealPolicy(config Config) concurrent.Option {
+	switch config.Steal {
+	case "", "one":
+		return concurrent.WithStealPolicy(concurrent.StealOne, 1)
+	case "half":
+		return concurrent.WithStealPolicy(concurrent.StealHalf, 1)
+	}
+	batch, err := strconv.Atoi(config.Steal)
+	if err != nil || batch < 1 {
+		panic("Invalid steal amount given.")
+	}
+	return concurrent.WithStealPolicy(concurrent.StealBatch, batch)
+}
+
+// Get the victim selector of the executors from the Victim field of the configuration value
+func victimSelector(config Config) concurrent.Option {
+	switch config.Victim {
+	case "", "random":
+		return concurrent.WithVictimSelector(concurrent.NewRandomVictimSelector)
+	case "roundrobin":
+		return concurrent.WithVictimSelector(concurrent.NewRoundRobinVictimSelector)
+	case "p2c":
+		return concurrent.WithVictimSelector(concurrent.NewPowerOfTwoVictimSelector)
+	case "last":
+		return concurrent.WithVictimSelector(concurrent.NewLastVictimSelector)
+	}
+	panic("Invalid victim selection given.")
+}
+
+// Get the balance policy of the work balancing executor from the Balance fields of the configuration value
+func balancePolicy(config Config) concurrent.Option {
+	// When to balance
+	var trigger concurrent.BalanceTrigger
+	switch config.BalanceTrigger {
+	case "", "random":
+		trigger = concurrent.TriggerRandom()
+	case "empty":
+		trigger = concurrent.TriggerOnEmpty()
+	default:
+		period, err := strconv.Atoi(config.BalanceTrigger)
+		if err != nil || period < 1 {
+			panic("Invalid balance trigger given.")
+		}
+		trigger = concurrent.TriggerPeriodic(period)
+	}
+
+	// Who to balance with
+	var pairing concurrent.BalancePairing
+	switch config.BalancePair {
+	case "", "victim":
+		pairing = concurrent.PairVictim()
+	case "imbalance":
+		pairing = concurrent.PairMostImbalanced()
+	default:
+		panic("Invalid balance pairing given.")
+	}
+
+	// How many tasks to move
+	var amount concurrent.BalanceAmount
+	switch config.BalanceAmount {
+	case "", "threshold":
+		amount = concurrent.MoveBelowThreshold()
+	case "equal":
+		amount = concurrent.MoveUntilEqual()
+	default:
+		count, err := strconv.Atoi(config.BalanceAmount)
+		if err != nil || count < 1 {
+			panic("Invalid balance amount given.")
+		}
+		amount = concurrent.MoveFixed(count)
+	}
+
+	return concurrent.WithBalancePolicy(concurrent.NewBalancePolicy(trigger, pairing, amount))
+}
+
+// Get the seed of the random decisions of the executors from the Seed field of the configuration value
+func seed(config Config) concurrent.Option {
+	if config.Seed == nil {
+		return concurrent.WithSeed(time.Now().UnixNano())
+	}
+	return concurrent.WithSeed(*config.Seed)
+}
+
+// Get the bound on the pending tasks of the executors from the MaxPending field of the configuration value
+func maxPending(config Config) concurrent.Option {
+	switch {
+	case config.MaxPending == 0:
+		// Keep every worker busy with one task waiting for each of them
+		return concurrent.WithMaxPending(2 * config.ThreadCount)
+	case config.MaxPending < 0:
+		return concurrent.WithMaxPending(0)
+	}
+	return concurrent.WithMaxPending(config.MaxPending)
+}
+
+// Get the task processing an image in the parallel versions from the StripeRows field of the configuration value
+func newImageTask(config Config, img *png.Image, outPath string, effects []string) interface{} {
+	if config.StripeRows > 0 {
+		return task.NewStripedImageTask(img, outPath, effects, config.StripeRows)
+	}
+	return task.NewImageTask(img, outPath, effects)
+}
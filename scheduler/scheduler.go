@@ -1,26 +1,177 @@
 package scheduler
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"proj3/concurrent"
+	"proj3/png"
+	"strings"
+	"time"
+)
+
 type Config struct {
 	DataDirs string //Represents the data directories to use to load the images.
 	Mode     string // Represents which scheduler scheme to use
 	// If Mode == "s" run the sequential version
 	// If Mode == "wb" run the work balancing version
 	// If Mode == "ws" run the work stealing version
+	// If Mode == "wsh" run the work sharing version
 	// These are the only values for Version
 	ThreadCount int // Runs the parallel version of the program with the
 	// specified number of threads (i.e., goroutines)
-	Threshold int // The threshold for the work stealing and work balancing
+	Threshold int    // The threshold for the work stealing and work balancing
+	Steal     string // The amount of tasks stolen at once in the work stealing version
+	// If Steal == "" or Steal == "one" steal a single task
+	// If Steal == "half" steal half of the victim's queue
+	// Otherwise Steal is a fixed number of tasks to steal at once
+	Victim string // How the work stealing and work balancing versions choose their victims
+	// If Victim == "" or Victim == "random" choose a random victim
+	// If Victim == "roundrobin" cycle through the other workers
+	// If Victim == "p2c" choose the longer queue of two random victims
+	// If Victim == "last" go back to the last successful victim
+	BalanceTrigger string // When the work balancing version balances
+	// If BalanceTrigger == "" or BalanceTrigger == "random" balance at random
+	// If BalanceTrigger == "empty" balance when the local queue is empty
+	// Otherwise BalanceTrigger is the number of iterations between two balancing operations
+	BalancePair string // Which worker the work balancing version balances with
+	// If BalancePair == "" or BalancePair == "victim" balance with the victim chosen by Victim
+	// If BalancePair == "imbalance" balance with the worker whose queue size differs the most
+	BalanceAmount string // How many tasks the work balancing version moves
+	// If BalanceAmount == "" or BalanceAmount == "threshold" move tasks until the difference is below Threshold
+	// If BalanceAmount == "equal" move tasks until the queues are equal
+	// Otherwise BalanceAmount is a fixed number of tasks to move
+	Seed *int64 // The seed all random decisions of the work stealing and work balancing versions derive from
+	// If Seed == nil a seed based on the current time is used (see Result.Seed)
+	Tracer *concurrent.Tracer // Records the scheduling events of the parallel versions
+	// If Tracer == nil the parallel versions are not traced
+	MaxPending int // The maximum number of images loaded but not processed yet in the parallel versions
+	// If MaxPending == 0 twice the number of threads is used
+	// If MaxPending < 0 every image is loaded as soon as possible
+	StripeRows int // Splits every image into stripes of rows processed by fork-join subtasks in the parallel versions
+	// If StripeRows == 0 every image is processed by a single task
+	// Otherwise stripes with at most StripeRows rows are not split any further
+}
+
+// Failure describes an image that could not be processed
+type Failure struct {
+	Path string // The input path if the image could not be loaded, the output path otherwise
+	Err  error  // Why the image could not be processed
+}
+
+func (failure Failure) String() string {
+	return fmt.Sprintf("%s: %v", failure.Path, failure.Err)
+}
+
+// Result of a run of the scheduler
+type Result struct {
+	Failures []Failure         // The images that could not be processed
+	Stats    *concurrent.Stats // The statistics of the executor (nil for the sequential version)
+	Seed     int64             // The seed used for the random decisions, to reproduce the run
 }
 
 // Run the correct version based on the Mode field of the configuration value
-func Schedule(config Config) {
+func Schedule(config Config) Result {
+	return ScheduleContext(context.Background(), config)
+}
+
+// Run the correct version based on the Mode field of the configuration value until all
+// tasks are done or the context is done (images that were not processed by then are skipped)
+func ScheduleContext(ctx context.Context, config Config) Result {
+	// Pick a seed so that it can be reported
+	seed := time.Now().UnixNano()
+	if config.Seed != nil {
+		seed = *config.Seed
+	}
+	config.Seed = &seed
+
+	var result Result
 	if config.Mode == "s" {
-		RunSequential(config)
+		result = Result{Failures: RunSequential(ctx, config), Stats: nil}
 	} else if config.Mode == "ws" {
-		RunWorkStealing(config)
+		result = RunWorkStealing(ctx, config)
 	} else if config.Mode == "wb" {
-		RunWorkBalancing(config)
+		result = RunWorkBalancing(ctx, config)
+	} else if config.Mode == "wsh" {
+		result = RunWorkSharing(ctx, config)
 	} else {
 		panic("Invalid scheduling scheme given.")
 	}
+	result.Seed = seed
+	return result
+}
+
+// Run a parallel version on the given executor: every image is submitted as a task and the
+// executor is shut down once all images are submitted or the context is done
+func runParallel(ctx context.Context, config Config, executor concurrent.ContextExecutorService) Result {
+	dataDirs := strings.Split(config.DataDirs, "+")
+	outputPath := "../data/out/%s_%s"
+	inputPath := "../data/in/%s/%s"
+
+	effectsPathFile := "../data/effects.txt"
+	effectsFile, err := os.Open(effectsPathFile)
+	if err != nil {
+		panic(err)
+	}
+	defer effectsFile.Close()
+
+	// Get the decoder
+	reader := json.NewDecoder(effectsFile)
+
+	// Keep track of the submitted images to report the ones that failed
+	failures := []Failure{}
+	outPaths := []string{}
+	futures := []concurrent.Future{}
+
+	// Decode the json requests in the effects file
+	for {
+		// Read the next request from the effects file
+		// If there are no more requests or the context is done, break
+		job := Job{}
+		err := reader.Decode(&job)
+		if err != nil || ctx.Err() != nil {
+			break
+		}
+
+		// Process the task
+		for _, dataDir := range dataDirs {
+			inPath := fmt.Sprintf(inputPath, dataDir, job.InPath)
+			outPath := fmt.Sprintf(outputPath, dataDir, job.OutPath)
+
+			// Read the input file
+			img, err := png.Load(inPath)
+			if err != nil {
+				failures = append(failures, Failure{Path: inPath, Err: err})
+				continue
+			}
+
+			// Add image task to the work pool, this blocks while too many images are pending so
+			// that only a bounded number of images is held in memory
+			imageTask := newImageTask(config, img, outPath, job.Effects)
+			outPaths = append(outPaths, outPath)
+			futures = append(futures, executor.SubmitContext(ctx, imageTask))
+		}
+	}
+
+	// Shutdown the service
+	executor.Shutdown()
+
+	// Report the images whose task failed along with the statistics of the executor
+	stats := executor.(concurrent.StatsExecutorService).Stats()
+	return Result{
+		Failures: append(failures, collectFailures(outPaths, futures)...),
+		Stats:    &stats,
+	}
+}
+
+// Get the failures of the image tasks whose future resolved with an error
+func collectFailures(outPaths []string, futures []concurrent.Future) []Failure {
+	failures := []Failure{}
+	for i, future := range futures {
+		if err := future.(concurrent.ErrFuture).Err(); err != nil {
+			failures = append(failures, Failure{Path: outPaths[i], Err: err})
+		}
+	}
+	return failures
 }
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,7 +14,7 @@ type Job = task.Job
 type Image = png.Image
 
 // Run the sequential model for generating and performing the tasks
-func RunSequential(config Config) {
+func RunSequential(ctx context.Context, config Config) []Failure {
 	dataDirs := strings.Split(config.DataDirs, "+")
 	outputPath := "../data/out/%s_%s"
 	inputPath := "../data/in/%s/%s"
@@ -28,13 +29,16 @@ func RunSequential(config Config) {
 	// Get the decoder
 	reader := json.NewDecoder(effectsFile)
 
+	// Keep track of the images that failed
+	failures := []Failure{}
+
 	// Decode the json requests in the effects file
 	for {
 		// Read the next request from the effects file
-		// If there are no more requests, break
+		// If there are no more requests or the context is done, break
 		job := Job{}
 		err := reader.Decode(&job)
-		if err != nil {
+		if err != nil || ctx.Err() != nil {
 			break
 		}
 
@@ -46,7 +50,8 @@ func RunSequential(config Config) {
 			// Read the input file
 			img, err := png.Load(inPath)
 			if err != nil {
-				panic(err)
+				failures = append(failures, Failure{Path: inPath, Err: err})
+				continue
 			}
 
 			// Create the image task
@@ -56,11 +61,36 @@ func RunSequential(config Config) {
 				Effects:    job.Effects,
 			}
 
-			// Process the effects
-			imageTask.ApplyEffects(img.Bounds.Min.Y, img.Bounds.Max.Y)
+			// Process the image unless the context is done
+			err = runSequential(ctx, &imageTask)
+			if ctx.Err() != nil {
+				return failures
+			}
+			if err != nil {
+				failures = append(failures, Failure{Path: outPath, Err: err})
+			}
+		}
+	}
+
+	return failures
+}
 
-			// Save the output file
-			imageTask.SaveResult()
+// Apply the effects and save the output file of an image task, a panic (e.g. an invalid
+// effect or a failed save) is recovered and returned as an error
+func runSequential(ctx context.Context, imageTask *task.ImageTask) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
 		}
+	}()
+
+	// Process the effects
+	bounds := imageTask.Image.Bounds
+	if err := imageTask.ApplyEffectsContext(ctx, bounds.Min.Y, bounds.Max.Y); err != nil {
+		return err
 	}
+
+	// Save the output file
+	imageTask.SaveResult()
+	return nil
 }
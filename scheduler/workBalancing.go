@@ -1,63 +1,15 @@
 package scheduler
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
+	"context"
 	"proj3/concurrent"
-	"proj3/png"
-	"proj3/task"
-	"strings"
 )
 
 // Run the work balancing model for generating and performing the tasks
-func RunWorkBalancing(config Config) {
+func RunWorkBalancing(ctx context.Context, config Config) Result {
 	if config.Threshold == 0 {
 		config.Threshold = 1
 	}
-	executor := concurrent.NewWorkBalancingExecutor(config.ThreadCount, 1, config.Threshold)
-
-	dataDirs := strings.Split(config.DataDirs, "+")
-	outputPath := "../data/out/%s_%s"
-	inputPath := "../data/in/%s/%s"
-
-	effectsPathFile := "../data/effects.txt"
-	effectsFile, err := os.Open(effectsPathFile)
-	if err != nil {
-		panic(err)
-	}
-	defer effectsFile.Close()
-
-	// Get the decoder
-	reader := json.NewDecoder(effectsFile)
-
-	// Decode the json requests in the effects file
-	for {
-		// Read the next request from the effects file
-		// If there are no more requests, break
-		job := Job{}
-		err := reader.Decode(&job)
-		if err != nil {
-			break
-		}
-
-		// Process the task
-		for _, dataDir := range dataDirs {
-			inPath := fmt.Sprintf(inputPath, dataDir, job.InPath)
-			outPath := fmt.Sprintf(outputPath, dataDir, job.OutPath)
-
-			// Read the input file
-			img, err := png.Load(inPath)
-			if err != nil {
-				panic(err)
-			}
-
-			// Add image task to the work pool
-			imageTask := task.NewImageTask(img, outPath, job.Effects)
-			executor.Submit(imageTask)
-		}
-	}
-
-	// Shutdown the service
-	executor.Shutdown()
+	executor := concurrent.NewWorkBalancingExecutor(config.ThreadCount, 1, config.Threshold, victimSelector(config), seed(config), balancePolicy(config), concurrent.WithTracer(config.Tracer), maxPending(config)).(concurrent.ContextExecutorService)
+	return runParallel(ctx, config, executor)
 }
@@ -0,0 +1,12 @@
+package scheduler
+
+import (
+	"context"
+	"proj3/concurrent"
+)
+
+// Run the work sharing model for generating and performing the tasks
+func RunWorkSharing(ctx context.Context, config Config) Result {
+	executor := concurrent.NewWorkSharingExecutor(config.ThreadCount, concurrent.WithTracer(config.Tracer), maxPending(config)).(concurrent.ContextExecutorService)
+	return runParallel(ctx, config, executor)
+}
@@ -0,0 +1,99 @@
+package task
+
+import (
+	"proj3/concurrent"
+)
+
+// StripedImageTask is an ImageTask that splits every effect into stripes of rows that are
+// processed recursively by fork-join subtasks when it is run by an executor
+type StripedImageTask struct {
+	*ImageTask
+	MinRows int // Stripes with at most MinRows rows are not split any further
+}
+
+// Create a new striped task
+func NewStripedImageTask(image *Image, outputPath string, effects []string, minRows int) interface{} {
+	// Split down to single rows at most
+	if minRows < 1 {
+		minRows = 1
+	}
+	task := &StripedImageTask{
+		ImageTask: &ImageTask{
+			Image:      image,
+			OutputPath: outputPath,
+			Effects:    effects,
+		},
+		MinRows: minRows,
+	}
+	return task
+}
+
+// Apply the effects stripe by stripe and save the result, the output file is not saved if the
+// context of the task is done before all effects are applied (the error of the context is
+// returned then)
+func (task *StripedImageTask) Compute(fj concurrent.ForkJoin) interface{} {
+	// Process all effects and swap the buffers after each effect (once all stripes are done)
+	for _, effect := range task.Effects {
+		if err := fj.Context().Err(); err != nil {
+			return err
+		}
+		stripe := &stripeTask{
+			image:   task.Image,
+			effect:  effect,
+			startY:  0,
+			endY:    task.Image.Bounds.Max.Y,
+			minRows: task.MinRows,
+		}
+		stripe.Compute(fj)
+		task.Image.Swap()
+	}
+	// Swap the buffers back to the output
+	task.Image.Swap()
+
+	// Save the output file
+	task.SaveResult()
+	return nil
+}
+
+// Applies an effect to the rows [startY, endY) of an image
+type stripeTask struct {
+	image   *Image
+	effect  string
+	startY  int
+	endY    int
+	minRows int
+}
+
+// Apply the effect, splitting the stripe in two halves until it is small enough
+func (stripe *stripeTask) Compute(fj concurrent.ForkJoin) interface{} {
+	if stripe.endY-stripe.startY <= stripe.minRows {
+		stripe.image.ApplyEffect(stripe.effect, stripe.startY, stripe.endY)
+		return nil
+	}
+
+	// Fork the lower half and process the upper half in the meantime
+	middle := (stripe.startY + stripe.endY) / 2
+	lower := fj.Fork(&stripeTask{
+		image:   stripe.image,
+		effect:  stripe.effect,
+		startY:  middle,
+		endY:    stripe.endY,
+		minRows: stripe.minRows,
+	})
+	upper := &stripeTask{
+		image:   stripe.image,
+		effect:  stripe.effect,
+		startY:  stripe.startY,
+		endY:    middle,
+		minRows: stripe.minRows,
+	}
+	upper.Compute(fj)
+	fj.Join(lower)
+
+	// Fail the whole task if the lower half failed (e.g. it panicked), a cancelled context is
+	// handled by the task itself
+	if err := lower.(concurrent.ErrFuture).Err(); err != nil && fj.Context().Err() == nil {
+		panic(err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package task
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"proj3/concurrent"
+	imagepng "proj3/png"
+)
+
+// Write a synthetic image with an irregular pattern so that every effect changes it
+func writeTestImage(t *testing.T, path string, width, height int) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x * y), A: 255})
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadTestImage(t *testing.T, path string) *imagepng.Image {
+	img, err := imagepng.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// Striping an image does not change the output whatever the size of the stripes
+func TestStripedImageTask(t *testing.T) {
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.png")
+	writeTestImage(t, inPath, 40, 37)
+	effects := []string{"G", "E", "S", "B"}
+
+	sequentialPath := filepath.Join(dir, "sequential.png")
+	NewImageTask(loadTestImage(t, inPath), sequentialPath, effects).(*ImageTask).Run()
+	expected, err := os.ReadFile(sequentialPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executor := concurrent.NewWorkStealingExecutor(4, 1)
+	defer executor.Shutdown()
+	for _, minRows := range []int{0, 1, 5, 100} {
+		stripedPath := filepath.Join(dir, "striped.png")
+		future := executor.Submit(NewStripedImageTask(loadTestImage(t, inPath), stripedPath, effects, minRows))
+		if err := future.(concurrent.ErrFuture).Err(); err != nil {
+			t.Fatalf("striped task (%d rows) failed with %v", minRows, err)
+		}
+		actual, err := os.ReadFile(stripedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("striped output (%d rows) differs from the sequential output", minRows)
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package task
 
 import (
+	"context"
 	"proj3/png"
-	"sync"
 )
 
 type Image = png.Image
@@ -17,34 +17,37 @@ type ImageTask struct {
 	Image      *Image
 	OutputPath string
 	Effects    []string
-	cond       *sync.Cond
-	done       bool
 }
 
 // Create a new task
 func NewImageTask(image *Image, outputPath string, effects []string) interface{} {
-	// Create a new condition variable
-	cond := sync.NewCond(&sync.Mutex{})
 	// Create a new task
 	task := &ImageTask{
 		Image:      image,
 		OutputPath: outputPath,
 		Effects:    effects,
-		cond:       cond,
-		done:       false,
 	}
 	return task
 }
 
 // Apply the effects to the image
 func (t *ImageTask) ApplyEffects(startY, endY int) {
+	t.ApplyEffectsContext(context.Background(), startY, endY)
+}
+
+// Apply the effects to the image, stopping before the next effect once the context is done
+func (t *ImageTask) ApplyEffectsContext(ctx context.Context, startY, endY int) error {
 	// Process all effects and swap the buffers after each effect
 	for _, effect := range t.Effects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		t.Image.ApplyEffect(effect, startY, endY)
 		t.Image.Swap()
 	}
 	// Swap the buffers back to the output
 	t.Image.Swap()
+	return nil
 }
 
 func (t *ImageTask) SaveResult() {
@@ -55,32 +58,27 @@ func (t *ImageTask) SaveResult() {
 	}
 }
 
+// Name the task after its output file (e.g. in traces of the executors)
+func (task *ImageTask) String() string {
+	return task.OutputPath
+}
+
 // Run the task
 func (task *ImageTask) Run() {
 	// Process the effects
 	task.ApplyEffects(0, task.Image.Bounds.Max.Y)
 	// Save the output file
 	task.SaveResult()
-	// Signal that the task is complete
-	task.Done()
 }
 
-// Wait for the task to complete
-func (f *ImageTask) Get() interface{} {
-	// Wait for the barrier to be signaled
-	f.cond.L.Lock()
-	if !f.done {
-		f.cond.Wait()
+// Run the task, the output file is not saved if the context is done before all effects are
+// applied (the error of the context is returned then)
+func (task *ImageTask) RunContext(ctx context.Context) error {
+	// Process the effects
+	if err := task.ApplyEffectsContext(ctx, 0, task.Image.Bounds.Max.Y); err != nil {
+		return err
 	}
-	f.cond.L.Unlock()
+	// Save the output file
+	task.SaveResult()
 	return nil
 }
-
-// Indicates that the task is complete
-func (f *ImageTask) Done() {
-	// Signal that the barrier is complete
-	f.cond.L.Lock()
-	f.done = true
-	f.cond.Signal()
-	f.cond.L.Unlock()
-}